@@ -0,0 +1,50 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"melhorzin-lang/internal/convert"
+	"os"
+)
+
+func main() {
+	to := flag.String("to", "text", "superfície de destino: emoji ou text")
+	write := flag.Bool("w", false, "sobrescreve o arquivo em vez de imprimir o resultado")
+	flag.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Uso: converter [-to emoji|text] [-w] <arquivo.mlz>")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(1)
+	}
+
+	surface, err := convert.ParseSurface(*to)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Erro: %v\n", err)
+		os.Exit(1)
+	}
+
+	filename := flag.Arg(0)
+	code, err := os.ReadFile(filename)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Erro ao ler arquivo: %v\n", err)
+		os.Exit(1)
+	}
+
+	result, err := convert.Convert(string(code), surface)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Erro: %v\n", err)
+		os.Exit(1)
+	}
+	if !*write {
+		fmt.Print(result)
+		return
+	}
+	if err := os.WriteFile(filename, []byte(result), 0644); err != nil {
+		fmt.Fprintf(os.Stderr, "Erro ao escrever arquivo: %v\n", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"melhorzin-lang/internal/ast"
+	"melhorzin-lang/internal/format"
+	"melhorzin-lang/internal/lexer"
+	"os"
+	"strings"
+)
+
+// fmtCommand formata programas no layout canônico. Sem opções, mostra o
+// código formatado; com --check, lista os arquivos que mudariam e falha se
+// houver algum; com --write, reescreve os arquivos. --check e --write
+// aceitam arquivos e diretórios (por padrão, o diretório atual).
+func fmtCommand(args []string) int {
+	opts, args := parseFlags("fmt", args, "e", "check", "write", "json", "color", "text")
+	if opts.check && opts.write {
+		return opts.finish("", &usageError{"--check e --write não podem ser usados juntos"})
+	}
+	if !opts.check && !opts.write {
+		return fmtPrint(opts, args)
+	}
+	if opts.code != "" || (len(args) > 0 && args[0] == "-") {
+		return opts.finish("", &usageError{"--check e --write só aceitam arquivos e diretórios"})
+	}
+	if len(args) == 0 {
+		args = []string{"."}
+	}
+
+	files, err := findSources(args)
+	if err != nil {
+		return opts.finish("", err)
+	}
+	code := exitOK
+	for _, file := range files {
+		changed, err := fmtFile(file, opts.keywords(), opts.write)
+		if err != nil {
+			code = max(code, opts.finish(file, err))
+			continue
+		}
+		if changed && opts.check {
+			code = max(code, exitFailure)
+		}
+		if opts.json {
+			r := newReport(file, nil)
+			if changed && opts.check {
+				r.Status = "fail"
+			}
+			writeJSON(r)
+		} else if changed && opts.check {
+			fmt.Println(file)
+		}
+	}
+	return code
+}
+
+// fmtPrint mostra o programa indicado (arquivo, - ou -e) formatado
+func fmtPrint(opts *options, args []string) int {
+	src, name, rest, err := openSource(opts, args)
+	if err != nil {
+		return opts.finish(name, err)
+	}
+	code, err := io.ReadAll(src)
+	src.Close()
+	if err != nil {
+		return opts.finish(name, fmt.Errorf("Erro ao ler arquivo: %w", err))
+	}
+	if len(rest) > 0 {
+		return opts.finish(name, &usageError{"argumentos inesperados: " + strings.Join(rest, " ")})
+	}
+
+	formatted, err := formatSource(string(code), opts.keywords())
+	if err != nil {
+		return opts.finish(name, err)
+	}
+	if opts.json {
+		r := newReport(name, nil)
+		r.Result = formatted
+		writeJSON(r)
+		return exitOK
+	}
+	fmt.Print(formatted)
+	return exitOK
+}
+
+// fmtFile formata um arquivo e indica se o resultado é diferente do original.
+// Com write, o arquivo é reescrito se mudou.
+func fmtFile(file string, keywords *lexer.Keywords, write bool) (changed bool, err error) {
+	code, err := os.ReadFile(file)
+	if err != nil {
+		return false, fmt.Errorf("Erro ao ler arquivo: %w", err)
+	}
+	formatted, err := formatSource(string(code), keywords)
+	if err != nil || formatted == string(code) {
+		return false, err
+	}
+	if write {
+		if err := os.WriteFile(file, []byte(formatted), 0o644); err != nil {
+			return true, fmt.Errorf("Erro ao escrever arquivo: %w", err)
+		}
+	}
+	return true, nil
+}
+
+// formatSource analisa um programa e retorna o código formatado
+func formatSource(code string, keywords *lexer.Keywords) (string, error) {
+	nodes, err := parse(strings.NewReader(code), keywords, make(map[string]ast.Type))
+	if err != nil {
+		return "", err
+	}
+	config := &format.Config{Keywords: keywords}
+	formatted, err := config.Source(code, nodes)
+	var sourceErr *lexer.Error
+	if errors.As(err, &sourceErr) {
+		return "", newSyntaxError(code, []*lexer.Error{sourceErr})
+	}
+	if err != nil {
+		return "", err
+	}
+	return formatted, nil
+}
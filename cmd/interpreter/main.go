@@ -1,35 +1,351 @@
 package main
 
 import (
+	"errors"
+	"flag"
 	"fmt"
-	"io/ioutil"
+	"io"
+	"io/fs"
+	"melhorzin-lang/internal/ast"
 	"melhorzin-lang/internal/interpreter"
 	"melhorzin-lang/internal/lexer"
 	"melhorzin-lang/internal/parser"
+	"melhorzin-lang/internal/repl"
 	"os"
+	"path/filepath"
+	"slices"
+	"strings"
 )
 
+const usage = `Uso: emojilang <comando> [opções] [arquivo.mlz | -] [argumentos]
+
+Comandos:
+  run     executa um programa (o padrão se o primeiro argumento não é um comando)
+  check   verifica a sintaxe sem executar
+  tokens  lista os tokens do programa
+  ast     mostra a árvore sintática (AST) do programa
+  fmt     formata o código no layout canônico
+  test    executa os testes: arquivos .mlz com a saída esperada em um .out
+  repl    abre o modo interativo (o padrão sem argumentos)
+  help    mostra esta ajuda
+
+Opções:
+  -e código        usa o código informado em vez de um arquivo
+  --text           aceita a sintaxe textual (print, let, fn, ...)
+  --no-result      não mostra o resultado final (run)
+  --json           saída em JSON, um objeto por linha
+  --color modo     cores na saída: auto, always ou never
+  --check          lista os arquivos que não estão formatados (fmt)
+  --write          reescreve os arquivos formatados (fmt)
+
+Com - no lugar do arquivo, o programa é lido da entrada padrão. Os
+argumentos depois do arquivo ficam na lista argumentos do programa.
+
+Códigos de saída:
+  0  sucesso
+  1  falha: testes que falharam, arquivos não formatados, erro ao ler arquivos
+  2  uso incorreto
+  3  erro de sintaxe
+  4  erro de execução não capturado pelo programa
+  5  execução interrompida (Ctrl-C)
+  ou o código passado pelo programa para sair(código)`
+
+// Códigos de saída do processo
+const (
+	exitOK          = 0
+	exitFailure     = 1
+	exitUsage       = 2
+	exitSyntax      = 3
+	exitException   = 4
+	exitInterrupted = 5
+)
+
+// commands são os subcomandos; cada um recebe os argumentos depois do nome e
+// retorna o código de saída
+var commands = map[string]func(args []string) int{
+	"run":    runCommand,
+	"check":  checkCommand,
+	"tokens": tokensCommand,
+	"ast":    astCommand,
+	"fmt":    fmtCommand,
+	"test":   testCommand,
+	"repl":   replCommand,
+}
+
 func main() {
-	if len(os.Args) < 2 {
-		fmt.Println("Uso: emojilang <arquivo.mlz>")
-		os.Exit(1)
+	args := os.Args[1:]
+	if len(args) == 0 {
+		os.Exit(replCommand(nil))
+	}
+
+	switch args[0] {
+	case "help", "-h", "-help", "--help":
+		fmt.Println(usage)
+		os.Exit(exitOK)
+	}
+	if command, ok := commands[args[0]]; ok {
+		os.Exit(command(args[1:]))
+	}
+	// emojilang arquivo.mlz, emojilang - e emojilang -e 'código'
+	os.Exit(runCommand(args))
+}
+
+// options são as opções de linha de comando comuns aos subcomandos
+type options struct {
+	code     string // Código passado com -e
+	noResult bool
+	json     bool
+	color    bool
+	check    bool
+	write    bool
+	text     bool // Aceitar a sintaxe textual
+}
+
+// parseFlags analisa as opções de um subcomando, aceitando só as listadas em
+// names, e retorna os argumentos restantes. Em caso de erro, mostra o uso e
+// encerra o processo.
+func parseFlags(command string, args []string, names ...string) (*options, []string) {
+	opts := &options{}
+	flags := flag.NewFlagSet(command, flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	color := "auto"
+	for _, name := range names {
+		switch name {
+		case "e":
+			flags.StringVar(&opts.code, "e", "", "")
+		case "no-result":
+			flags.BoolVar(&opts.noResult, "no-result", false, "")
+		case "json":
+			flags.BoolVar(&opts.json, "json", false, "")
+		case "color":
+			flags.StringVar(&color, "color", "auto", "")
+		case "check":
+			flags.BoolVar(&opts.check, "check", false, "")
+		case "write":
+			flags.BoolVar(&opts.write, "write", false, "")
+		case "text":
+			flags.BoolVar(&opts.text, "text", false, "")
+		}
+	}
+
+	err := flags.Parse(args)
+	if err == nil {
+		switch color {
+		case "auto":
+			opts.color = os.Getenv("NO_COLOR") == "" && isTerminal(os.Stdout)
+		case "always", "never":
+			opts.color = color == "always"
+		default:
+			err = fmt.Errorf("valor inválido para --color: %s", color)
+		}
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "emojilang %s: %v\n\n%s\n", command, err, usage)
+		os.Exit(exitUsage)
+	}
+	return opts, flags.Args()
+}
+
+// keywords retorna o dicionário de palavras-chave dos programas: a tabela
+// padrão ou, com --text, também a sintaxe textual
+func (o *options) keywords() *lexer.Keywords {
+	if o.text {
+		return lexer.TextKeywords()
 	}
+	return lexer.DefaultKeywords()
+}
+
+// isTerminal indica se f é um terminal, para decidir se a saída tem cores
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// Cores ANSI usadas na saída
+const (
+	colorRed   = "31"
+	colorGreen = "32"
+	colorCyan  = "36"
+)
+
+// paint colore text, se as cores estiverem ativas
+func (o *options) paint(color, text string) string {
+	if !o.color {
+		return text
+	}
+	return "\x1b[" + color + "m" + text + "\x1b[0m"
+}
 
-	filename := os.Args[1]
-	code, err := ioutil.ReadFile(filename)
+// openSource abre o programa indicado pelos argumentos: o código de -e, a
+// entrada padrão (-) ou um arquivo. Retorna também o nome usado nas
+// mensagens e os argumentos restantes.
+func openSource(opts *options, args []string) (src io.ReadCloser, name string, rest []string, err error) {
+	if opts.code != "" {
+		return io.NopCloser(strings.NewReader(opts.code)), "-e", args, nil
+	}
+	if len(args) == 0 {
+		return nil, "", nil, &usageError{"nenhum arquivo informado"}
+	}
+	if args[0] == "-" {
+		return io.NopCloser(os.Stdin), "<stdin>", args[1:], nil
+	}
+	file, err := os.Open(args[0])
 	if err != nil {
-		fmt.Printf("Erro ao ler arquivo: %v\n", err)
-		os.Exit(1)
+		return nil, args[0], nil, fmt.Errorf("Erro ao ler arquivo: %w", err)
+	}
+	return file, args[0], args[1:], nil
+}
+
+// findSources procura os arquivos .mlz nos diretórios (recursivamente) e
+// arquivos informados, em ordem
+func findSources(paths []string) ([]string, error) {
+	var sources []string
+	for _, path := range paths {
+		err := filepath.WalkDir(path, func(file string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if !entry.IsDir() && filepath.Ext(file) == ".mlz" {
+				sources = append(sources, file)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("Erro ao procurar arquivos: %w", err)
+		}
+	}
+	slices.Sort(sources)
+	return slices.Compact(sources), nil
+}
+
+// usageError indica argumentos de linha de comando inválidos
+type usageError struct {
+	message string
+}
+
+func (e *usageError) Error() string {
+	return e.message
+}
+
+// syntaxError reúne os erros léxicos e o erro do parser de um programa, com a
+// linha e a coluna em que cada um ocorreu
+type syntaxError struct {
+	errors []sourceError
+}
+
+// sourceError é um erro em uma linha e coluna do programa
+type sourceError struct {
+	line, column int
+	message      string
+}
+
+// newSyntaxError cria o erro de sintaxe dos erros encontrados em source
+func newSyntaxError(source string, errs []*lexer.Error) *syntaxError {
+	lines := lexer.NewLineIndex(source)
+	e := &syntaxError{}
+	for _, err := range errs {
+		line, column := lines.Position(err.Pos)
+		e.errors = append(e.errors, sourceError{line, column, err.Message})
+	}
+	return e
+}
+
+func (e *syntaxError) Error() string {
+	return e.describe("")
+}
+
+// describe formata um erro por linha, no formato arquivo:linha:coluna:
+// mensagem; sem file, só linha:coluna: mensagem
+func (e *syntaxError) describe(file string) string {
+	prefix := ""
+	if file != "" {
+		prefix = file + ":"
+	}
+	messages := make([]string, len(e.errors))
+	for i, err := range e.errors {
+		messages[i] = fmt.Sprintf("%s%d:%d: Erro de sintaxe: %s", prefix, err.line, err.column, err.message)
+	}
+	return strings.Join(messages, "\n")
+}
+
+// parse analisa um programa inteiro com as palavras-chave de keywords,
+// partindo dos tipos das variáveis já definidas em types. Os erros do lexer,
+// que normalmente só seriam escritos na saída, também tornam o programa
+// inválido.
+func parse(src io.Reader, keywords *lexer.Keywords, types map[string]ast.Type) (nodes []ast.Node, err error) {
+	// O código lido é guardado para converter as posições dos erros em
+	// linha e coluna
+	var code strings.Builder
+	lex := lexer.NewReaderLexerWithKeywords(io.TeeReader(src, &code), keywords)
+	lex.SetErrorOutput(io.Discard)
+	p := parser.NewParserWithTypes(lex, types)
+	defer func() {
+		errs := slices.Clone(lex.Errors())
+		if r := recover(); r != nil {
+			message, ok := r.(string)
+			if !ok {
+				panic(r)
+			}
+			errs = append(errs, &lexer.Error{Pos: p.Pos(), Message: message})
+		}
+		if len(errs) > 0 {
+			nodes, err = nil, newSyntaxError(code.String(), errs)
+		}
+	}()
+	return p.Parse(), nil
+}
+
+// exitCode retorna o código de saída correspondente a um erro
+func exitCode(err error) int {
+	var (
+		usage     *usageError
+		syntax    *syntaxError
+		exception *interpreter.RuntimeError
+		interrupt *interpreter.Interrupt
+		exit      *interpreter.Exit
+	)
+	switch {
+	case err == nil:
+		return exitOK
+	case errors.As(err, &exit):
+		return exit.Code
+	case errors.As(err, &usage):
+		return exitUsage
+	case errors.As(err, &syntax):
+		return exitSyntax
+	case errors.As(err, &exception):
+		return exitException
+	case errors.As(err, &interrupt):
+		return exitInterrupted
 	}
+	return exitFailure
+}
 
-	lex := lexer.NewLexer(string(code))
-	tokens := lex.Lex()
-	pars := parser.NewParser(tokens)
-	nodes := pars.Parse()
-	interp := interpreter.NewInterpreter()
+// describeError formata um erro do programa file para o usuário. Erros de
+// sintaxe mostram o arquivo, a linha e a coluna; erros de execução não
+// capturados mostram o tipo do erro, que é o que um 🤦🏿‍♂️ receberia.
+func describeError(file string, err error) string {
+	var (
+		syntax    *syntaxError
+		exception *interpreter.RuntimeError
+	)
+	switch {
+	case errors.As(err, &syntax):
+		return syntax.describe(file)
+	case errors.As(err, &exception):
+		return fmt.Sprintf("Erro não capturado (%s): %s", exception.Kind, exception.Message)
+	}
+	return err.Error()
+}
 
-	result := interp.Interpret(nodes)
-	if result != nil {
-		fmt.Printf("Resultado final: %v\n", result)
+func replCommand(args []string) int {
+	if len(args) > 0 {
+		fmt.Fprintf(os.Stderr, "emojilang repl: argumentos inesperados: %s\n", strings.Join(args, " "))
+		return exitUsage
+	}
+	if err := repl.New(os.Stdin, os.Stdout).Run(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitFailure
 	}
+	return exitOK
 }
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// Com EMOJILANG_MAIN definida, o binário de teste roda o emojilang, para que
+// os testes vejam a saída e o código de saída de verdade
+func TestMain(m *testing.M) {
+	if os.Getenv("EMOJILANG_MAIN") != "" {
+		os.Args = append([]string{"emojilang"}, os.Args[1:]...)
+		main()
+		os.Exit(exitOK)
+	}
+	os.Exit(m.Run())
+}
+
+// emojilang executa o emojilang com args e stdin, retornando a saída padrão,
+// a saída de erros e o código de saída
+func emojilang(t *testing.T, stdin string, args ...string) (stdout, stderr string, code int) {
+	t.Helper()
+	cmd := exec.Command(os.Args[0], args...)
+	cmd.Env = append(os.Environ(), "EMOJILANG_MAIN=1")
+	cmd.Stdin = strings.NewReader(stdin)
+	var out, errOut strings.Builder
+	cmd.Stdout, cmd.Stderr = &out, &errOut
+	err := cmd.Run()
+	var exit *exec.ExitError
+	if err != nil && !errors.As(err, &exit) {
+		t.Fatal(err)
+	}
+	return out.String(), errOut.String(), cmd.ProcessState.ExitCode()
+}
+
+func TestExitCodes(t *testing.T) {
+	dir := t.TempDir()
+	program := filepath.Join(dir, "args.mlz")
+	if err := os.WriteFile(program, []byte("🖨️ argumentos\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		stdin  string
+		args   []string
+		code   int
+		stdout string // Saída padrão esperada; ignorada se vazia
+		stderr string // Trecho esperado na saída de erros
+	}{
+		{"sucesso", "", []string{"-e", "🖨️ 1"}, exitOK, "1\nResultado final: 1\n", ""},
+		{"sem resultado", "", []string{"run", "--no-result", "-e", "🖨️ 1"}, exitOK, "1\n", ""},
+		{"entrada padrão", "🖨️ 2", []string{"--no-result", "-"}, exitOK, "2\n", ""},
+		{"argumentos", "", []string{"--no-result", program, "a", "b"}, exitOK, "[a, b]\n", ""},
+		{"erro de sintaxe", "", []string{"-e", "🖨️ (1"}, exitSyntax, "", "-e:1:6: Erro de sintaxe"},
+		{"erro léxico", "", []string{"-e", "🖨️ 1 $"}, exitSyntax, "", "Erro de sintaxe"},
+		{"token solto", "", []string{"-e", "✍️ z = 1 -- 2"}, exitSyntax, "", "Token inesperado DECREMENT"},
+		{"token solto em bloco", "", []string{"-e", "▶️ f() { = }"}, exitSyntax, "", "Token inesperado EQUALSIGN"},
+		{"erro de execução", "", []string{"-e", `1 + "a"`}, exitException, "", "Erro não capturado (TYPE)"},
+		{"erro capturado", "", []string{"--no-result", "-e", `👨🏿‍💻 { 1 + "a" } 🤦🏿‍♂️ { 🖨️ "ok" }`}, exitOK, "ok\n", ""},
+		{"sair", "", []string{"-e", "sair(7)"}, 7, "", ""},
+		{"check", "", []string{"check", "-e", "🖨️ 1"}, exitOK, "", ""},
+		{"check com erro", "", []string{"check", "-e", "🖨️ 1 }"}, exitSyntax, "", "Token inesperado RBRACE"},
+		{"opção desconhecida", "", []string{"--bogus", "-e", "1"}, exitUsage, "", "flag provided but not defined"},
+		{"opção de outro comando", "", []string{"check", "--write", "-e", "1"}, exitUsage, "", ""},
+		{"sem arquivo", "", []string{"run"}, exitUsage, "", "nenhum arquivo informado"},
+		{"arquivo inexistente", "", []string{filepath.Join(dir, "nao.mlz")}, exitFailure, "", ""},
+		{"ajuda", "", []string{"help"}, exitOK, "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stdout, stderr, code := emojilang(t, tt.stdin, tt.args...)
+			if code != tt.code {
+				t.Errorf("código de saída %d, esperado %d\nsaída: %s\nerros: %s", code, tt.code, stdout, stderr)
+			}
+			if tt.stdout != "" && stdout != tt.stdout {
+				t.Errorf("saída %q, esperado %q", stdout, tt.stdout)
+			}
+			if !strings.Contains(stderr, tt.stderr) {
+				t.Errorf("erros %q, esperado conter %q", stderr, tt.stderr)
+			}
+		})
+	}
+}
+
+func TestTokensJSON(t *testing.T) {
+	stdout, _, code := emojilang(t, "", "tokens", "--json", "-e", "🖨️1")
+	want := `{"type":"PRINT","value":"🖨️","pos":0,"end":7,"line":1,"column":1}
+{"type":"NUMBER","value":"1","pos":7,"end":8,"line":1,"column":3}
+{"type":"EOF","value":"","pos":8,"end":8,"line":1,"column":4}
+`
+	if code != exitOK || stdout != want {
+		t.Errorf("tokens --json = %d\n%s\nesperado\n%s", code, stdout, want)
+	}
+}
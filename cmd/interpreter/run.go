@@ -0,0 +1,246 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"melhorzin-lang/internal/ast"
+	"melhorzin-lang/internal/interpreter"
+	"melhorzin-lang/internal/lexer"
+	"os"
+	"os/signal"
+	"strings"
+)
+
+// report é o resultado de um comando na saída --json
+type report struct {
+	File   string       `json:"file,omitempty"`
+	Status string       `json:"status"` // ok, fail, usage, syntax, exception, interrupted, exit, error
+	Result interface{}  `json:"result,omitempty"`
+	Output string       `json:"output,omitempty"` // O que o programa imprimiu (run)
+	Code   int          `json:"code,omitempty"`   // Código passado para sair
+	Error  *errorReport `json:"error,omitempty"`
+}
+
+type errorReport struct {
+	Kind    interpreter.ErrorKind `json:"kind,omitempty"` // Só em erros de execução
+	Message string                `json:"message"`
+}
+
+// statuses é o status de cada código de saída em um report
+var statuses = map[int]string{
+	exitOK:          "ok",
+	exitFailure:     "error",
+	exitUsage:       "usage",
+	exitSyntax:      "syntax",
+	exitException:   "exception",
+	exitInterrupted: "interrupted",
+}
+
+// newReport cria o report de um arquivo que terminou com err
+func newReport(file string, err error) report {
+	r := report{File: file, Status: statuses[exitCode(err)]}
+	if exit, ok := err.(*interpreter.Exit); ok {
+		r.Status, r.Code = "exit", exit.Code
+		return r
+	}
+	if err != nil {
+		r.Error = &errorReport{Message: err.Error()}
+		var exception *interpreter.RuntimeError
+		if errors.As(err, &exception) {
+			r.Error.Kind, r.Error.Message = exception.Kind, exception.Message
+		}
+	}
+	return r
+}
+
+// writeJSON escreve um objeto JSON em uma linha da saída padrão
+func writeJSON(value interface{}) {
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetEscapeHTML(false)
+	encoder.Encode(value)
+}
+
+// finish reporta o erro de um comando, em JSON ou na saída de erros, e
+// retorna o código de saída. Um programa que chamou sair não é um erro: só o
+// código de saída é repassado.
+func (o *options) finish(file string, err error) int {
+	_, exited := err.(*interpreter.Exit)
+	if o.json {
+		writeJSON(newReport(file, err))
+	} else if err != nil && !exited {
+		fmt.Fprintln(os.Stderr, o.paint(colorRed, describeError(file, err)))
+	}
+	return exitCode(err)
+}
+
+// jsonValue converte um valor da linguagem para a saída JSON. Funções são
+// mostradas como texto.
+func jsonValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case int, string, bool, nil:
+		return value
+	case []interface{}:
+		items := make([]interface{}, len(v))
+		for i, item := range v {
+			items[i] = jsonValue(item)
+		}
+		return items
+	}
+	return interpreter.Stringify(value)
+}
+
+func runCommand(args []string) int {
+	opts, args := parseFlags("run", args, "e", "no-result", "json", "color", "text")
+	src, name, scriptArgs, err := openSource(opts, args)
+	if err != nil {
+		return opts.finish(name, err)
+	}
+	defer src.Close()
+
+	// Os argumentos depois do arquivo ficam disponíveis para o programa
+	interp := interpreter.NewInterpreter(interpreter.Trusted())
+	arguments := make([]interface{}, len(scriptArgs))
+	for i, arg := range scriptArgs {
+		arguments[i] = arg
+	}
+	interp.SetVariable("argumentos", arguments)
+
+	// Com --json, a saída padrão tem só o report: o que o programa imprime
+	// vai para o campo output
+	var output bytes.Buffer
+	if opts.json {
+		interp.SetEcho(false)
+		interp.SetIO(os.Stdin, &output, os.Stderr)
+	}
+
+	nodes, err := parse(src, opts.keywords(), interp.Types())
+	if err != nil {
+		return opts.finish(name, err)
+	}
+
+	// Ctrl-C interrompe o programa, que termina com exitInterrupted
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	result, err := interp.Interpret(ctx, nodes)
+
+	if opts.json {
+		r := newReport(name, err)
+		r.Output = output.String()
+		if err == nil && !opts.noResult {
+			r.Result = jsonValue(result)
+		}
+		writeJSON(r)
+		return exitCode(err)
+	}
+	if err != nil {
+		return opts.finish(name, err)
+	}
+	if result != nil && !opts.noResult {
+		fmt.Printf("Resultado final: %v\n", result)
+	}
+	return exitOK
+}
+
+// checkCommand verifica a sintaxe de um ou mais programas
+func checkCommand(args []string) int {
+	opts, args := parseFlags("check", args, "e", "json", "color", "text")
+	if opts.code != "" || len(args) <= 1 {
+		return opts.finish(check(opts, args))
+	}
+
+	code := exitOK
+	for _, arg := range args {
+		if result := opts.finish(check(opts, []string{arg})); result != exitOK {
+			code = max(code, result)
+		}
+	}
+	return code
+}
+
+// check analisa o programa indicado, sem executá-lo
+func check(opts *options, args []string) (string, error) {
+	src, name, rest, err := openSource(opts, args)
+	if err != nil {
+		return name, err
+	}
+	defer src.Close()
+	if len(rest) > 0 {
+		return name, &usageError{"argumentos inesperados: " + strings.Join(rest, " ")}
+	}
+	_, err = parse(src, opts.keywords(), make(map[string]ast.Type))
+	return name, err
+}
+
+// tokensCommand lista os tokens de um programa, um por linha, com a linha e
+// a coluna em que começam
+func tokensCommand(args []string) int {
+	opts, args := parseFlags("tokens", args, "e", "json", "color", "text")
+	src, name, _, err := openSource(opts, args)
+	if err != nil {
+		return opts.finish(name, err)
+	}
+	code, err := io.ReadAll(src)
+	src.Close()
+	if err != nil {
+		return opts.finish(name, fmt.Errorf("Erro ao ler arquivo: %w", err))
+	}
+
+	lines := lexer.NewLineIndex(string(code))
+	lex := lexer.NewLexerWithKeywords(string(code), opts.keywords())
+	lex.SetErrorOutput(io.Discard)
+	for token := range lex.Tokens() {
+		line, column := lines.Position(token.Pos)
+		if opts.json {
+			writeJSON(struct {
+				Type   lexer.TokenType `json:"type"`
+				Value  string          `json:"value"`
+				Pos    int             `json:"pos"`
+				End    int             `json:"end"`
+				Line   int             `json:"line"`
+				Column int             `json:"column"`
+			}{token.Type, token.Value, token.Pos, token.End, line, column})
+			continue
+		}
+		fmt.Printf("%d:%d\t%s\t%q\n", line, column, opts.paint(colorCyan, string(token.Type)), token.Value)
+	}
+
+	if errs := lex.Errors(); len(errs) > 0 {
+		return opts.finish(name, newSyntaxError(string(code), errs))
+	}
+	return exitOK
+}
+
+// astCommand mostra a AST produzida pelo parser, como árvore ou em JSON
+func astCommand(args []string) int {
+	opts, args := parseFlags("ast", args, "e", "json", "color", "text")
+	src, name, _, err := openSource(opts, args)
+	if err != nil {
+		return opts.finish(name, err)
+	}
+	code, err := io.ReadAll(src)
+	src.Close()
+	if err != nil {
+		return opts.finish(name, fmt.Errorf("Erro ao ler arquivo: %w", err))
+	}
+
+	nodes, err := parse(bytes.NewReader(code), opts.keywords(), make(map[string]ast.Type))
+	if err != nil {
+		return opts.finish(name, err)
+	}
+	if opts.json {
+		out, err := ast.MarshalJSON(string(code), nodes)
+		if err != nil {
+			return opts.finish(name, err)
+		}
+		os.Stdout.Write(out)
+		return exitOK
+	}
+	if err := ast.Fprint(os.Stdout, string(code), nodes); err != nil {
+		return opts.finish(name, err)
+	}
+	return exitOK
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"melhorzin-lang/internal/interpreter"
+	"melhorzin-lang/internal/lexer"
+	"os"
+	"os/signal"
+	"strings"
+)
+
+// testCommand executa os testes nos arquivos e diretórios informados (por
+// padrão, o diretório atual). Um teste é um arquivo .mlz acompanhado de um
+// .out com a saída esperada e, opcionalmente, de um .in com a entrada.
+func testCommand(args []string) int {
+	opts, args := parseFlags("test", args, "json", "color", "text")
+	if len(args) == 0 {
+		args = []string{"."}
+	}
+
+	tests, err := findTests(args)
+	if err != nil {
+		return opts.finish("", err)
+	}
+	if len(tests) == 0 {
+		if !opts.json {
+			fmt.Println("Nenhum teste encontrado")
+		}
+		return exitOK
+	}
+
+	// Ctrl-C interrompe o teste em andamento e os seguintes
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	failed := 0
+	for _, test := range tests {
+		err := runTest(ctx, test, opts.keywords())
+		if err != nil {
+			failed++
+		}
+		if opts.json {
+			r := newReport(test, err)
+			if err != nil && exitCode(err) == exitFailure {
+				r.Status = "fail"
+			}
+			writeJSON(r)
+		} else if err != nil {
+			fmt.Printf("%s %s\n    %s\n", opts.paint(colorRed, "FALHOU"), test, strings.ReplaceAll(describeError(test, err), "\n", "\n    "))
+		} else {
+			fmt.Printf("%s     %s\n", opts.paint(colorGreen, "ok"), test)
+		}
+		if ctx.Err() != nil {
+			return exitInterrupted
+		}
+	}
+
+	if !opts.json {
+		fmt.Printf("%d testes, %d falharam\n", len(tests), failed)
+	}
+	if failed > 0 {
+		return exitFailure
+	}
+	return exitOK
+}
+
+// findTests procura os arquivos .mlz que têm um .out ao lado, nos diretórios
+// (recursivamente) e arquivos informados
+func findTests(paths []string) ([]string, error) {
+	sources, err := findSources(paths)
+	if err != nil {
+		return nil, err
+	}
+	var tests []string
+	for _, file := range sources {
+		if _, err := os.Stat(strings.TrimSuffix(file, ".mlz") + ".out"); err == nil {
+			tests = append(tests, file)
+		}
+	}
+	return tests, nil
+}
+
+// runTest executa um teste e compara a saída com a esperada. A saída inclui
+// os erros, como seriam mostrados por emojilang run, então um .out também
+// pode esperar um erro.
+func runTest(ctx context.Context, file string, keywords *lexer.Keywords) error {
+	base := strings.TrimSuffix(file, ".mlz")
+	expected, err := os.ReadFile(base + ".out")
+	if err != nil {
+		return fmt.Errorf("Erro ao ler arquivo: %w", err)
+	}
+	source, err := os.ReadFile(file)
+	if err != nil {
+		return fmt.Errorf("Erro ao ler arquivo: %w", err)
+	}
+	input, err := os.ReadFile(base + ".in")
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("Erro ao ler arquivo: %w", err)
+	}
+
+	var output bytes.Buffer
+	interp := interpreter.NewInterpreter(interpreter.Trusted())
+	interp.SetIO(bytes.NewReader(input), &output, &output)
+	interp.SetVariable("argumentos", []interface{}{})
+	nodes, err := parse(bytes.NewReader(source), keywords, interp.Types())
+	if err == nil {
+		_, err = interp.Interpret(ctx, nodes)
+		var interrupt *interpreter.Interrupt
+		if errors.As(err, &interrupt) {
+			return err
+		}
+		if exit, ok := err.(*interpreter.Exit); ok && exit.Code == 0 {
+			err = nil
+		}
+	}
+	if err != nil {
+		fmt.Fprintln(&output, describeError("", err))
+	}
+	return compareOutput(string(expected), output.String())
+}
+
+// compareOutput retorna um erro que mostra a primeira linha diferente entre
+// a saída esperada e a obtida
+func compareOutput(expected, actual string) error {
+	if expected == actual {
+		return nil
+	}
+	want := strings.Split(expected, "\n")
+	got := strings.Split(actual, "\n")
+	for i := 0; ; i++ {
+		if i >= len(want) || i >= len(got) || want[i] != got[i] {
+			line := func(lines []string) string {
+				if i >= len(lines) {
+					return "(fim da saída)"
+				}
+				return fmt.Sprintf("%q", lines[i])
+			}
+			return fmt.Errorf("linha %d: esperado %s, obtido %s", i+1, line(want), line(got))
+		}
+	}
+}
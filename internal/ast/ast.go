@@ -0,0 +1,307 @@
+// Package ast define os nós da árvore sintática do emojilang. Os nós são só
+// dados: o parser os produz e cada back end (o interpretador em
+// internal/interpreter, o formatador, ferramentas de análise) decide o que
+// fazer com eles.
+package ast
+
+import "melhorzin-lang/internal/lexer"
+
+// Type representa um tipo de dados na linguagem.
+type Type string
+
+const (
+	TypeNumber Type = "NUMBER"
+	TypeString Type = "STRING"
+	TypeBool   Type = "BOOL"
+	TypeAny    Type = "ANY"
+)
+
+// Node representa um nó da AST.
+type Node interface {
+	// Tipo estático do nó, usado na verificação de tipos; TypeAny quando só
+	// é conhecido em tempo de execução
+	GetType() Type
+	// Trecho do código fonte de onde o nó foi analisado
+	GetSpan() Span
+}
+
+// Span é o trecho do código fonte ocupado por um nó, em bytes: de Pos
+// (inclusive) a End (exclusive). Nós criados fora do parser têm Span zero.
+type Span struct {
+	Pos int
+	End int
+}
+
+// GetSpan retorna o trecho do código ocupado pelo nó. Todos os nós embutem
+// um Span, que implementa esse método de Node.
+func (s Span) GetSpan() Span {
+	return s
+}
+
+// PrintNode para instruções de impressão.
+type PrintNode struct {
+	Span
+	Values []Node // Expressões a imprimir, separadas por espaço
+}
+
+func (n *PrintNode) GetType() Type {
+	return TypeString
+}
+
+// AssignNode para atribuições.
+type AssignNode struct {
+	Span
+	Name         string
+	Value        Node
+	DeclaredType Type // Tipo declarado explicitamente
+	InferredType Type // Tipo inferido do valor
+}
+
+func (n *AssignNode) GetType() Type {
+	if n.DeclaredType != TypeAny {
+		return n.DeclaredType
+	}
+
+	// Inferir tipo
+	return n.Value.GetType()
+}
+
+// EqualNode para comparações entre duas expressões quaisquer.
+type EqualNode struct {
+	Span
+	Left  Node
+	Right Node
+}
+
+func (n *EqualNode) GetType() Type {
+	return TypeBool
+}
+
+// BinaryOpNode para operações binárias
+type BinaryOpNode struct {
+	Span
+	Left  Node
+	Op    lexer.TokenType
+	Right Node
+}
+
+func (n *BinaryOpNode) GetType() Type {
+	if n.Op == lexer.TokenConcat {
+		return TypeString
+	}
+	return TypeNumber
+}
+
+// LogicalNode para os operadores lógicos and/or, com avaliação em curto-circuito
+type LogicalNode struct {
+	Span
+	Left  Node
+	Op    lexer.TokenType
+	Right Node
+}
+
+func (n *LogicalNode) GetType() Type {
+	return TypeBool
+}
+
+// UnaryOpNode para operações unárias
+type UnaryOpNode struct {
+	Span
+	Op      lexer.TokenType
+	Operand Node
+}
+
+func (n *UnaryOpNode) GetType() Type {
+	if n.Op == lexer.TokenNot {
+		return TypeBool
+	}
+	return TypeNumber
+}
+
+// VariableNode para acessar variáveis
+type VariableNode struct {
+	Span
+	Name string
+	Type Type // Tipo da variável
+}
+
+func (n *VariableNode) GetType() Type {
+	return n.Type
+}
+
+// CompoundAssignNode para atribuições compostas (+=, -=, *=, .=) e
+// incremento/decremento (++, --).
+type CompoundAssignNode struct {
+	Span
+	Name  string
+	Op    lexer.TokenType // Token da atribuição composta
+	Value Node            // Operando à direita; nil para ++ e --
+	Type  Type            // Tipo conhecido da variável
+}
+
+// CompoundOperators associa cada atribuição composta à operação binária
+// equivalente.
+var CompoundOperators = map[lexer.TokenType]lexer.TokenType{
+	lexer.TokenPlusAssign:   lexer.TokenPlus,
+	lexer.TokenMinusAssign:  lexer.TokenMinus,
+	lexer.TokenMultAssign:   lexer.TokenMult,
+	lexer.TokenConcatAssign: lexer.TokenConcat,
+	lexer.TokenIncrement:    lexer.TokenPlus,
+	lexer.TokenDecrement:    lexer.TokenMinus,
+}
+
+// BinaryOp monta a operação binária equivalente (x += y vira x + y).
+func (n *CompoundAssignNode) BinaryOp() *BinaryOpNode {
+	right := n.Value
+	if right == nil {
+		right = &NumberLiteralNode{Value: 1}
+	}
+	return &BinaryOpNode{
+		Left:  &VariableNode{Name: n.Name, Type: n.Type},
+		Op:    CompoundOperators[n.Op],
+		Right: right,
+	}
+}
+
+func (n *CompoundAssignNode) GetType() Type {
+	return n.BinaryOp().GetType()
+}
+
+// FunctionNode para definição de funções
+type FunctionNode struct {
+	Span
+	Name       string
+	Parameters []string
+	ParamTypes []Type // Tipos dos parâmetros
+	ReturnType Type   // Tipo de retorno
+	Body       []Node
+}
+
+func (n *FunctionNode) GetType() Type {
+	return n.ReturnType
+}
+
+// ReturnNode para retorno de valores
+type ReturnNode struct {
+	Span
+	Value Node
+}
+
+func (n *ReturnNode) GetType() Type {
+	return n.Value.GetType()
+}
+
+// FunctionCallNode para chamadas de função
+type FunctionCallNode struct {
+	Span
+	Name      string
+	Arguments []Node
+}
+
+func (n *FunctionCallNode) GetType() Type {
+	// Fixed: Vars is not globally defined, should be passed as parameter
+	// This returns a conservative TypeAny since actual type checking happens during evaluation
+	return TypeAny
+}
+
+// MainNode para a função main.
+type MainNode struct {
+	Span
+	Body []Node
+}
+
+func (n *MainNode) GetType() Type {
+	return TypeAny
+}
+
+// TryCatchNode para try-catch. O bloco try é executado até Attempts vezes;
+// se todas as tentativas lançarem um erro de execução, o bloco catch é
+// executado com a mensagem do último erro na variável ErrorVar.
+type TryCatchNode struct {
+	Span
+	Label     string // Nome do bloco, informado depois de 🚀
+	Attempts  int    // Número de tentativas do bloco try
+	ErrorVar  string // Variável que recebe a mensagem de erro no catch
+	TryBody   []Node
+	CatchBody []Node
+}
+
+func (n *TryCatchNode) GetType() Type {
+	return TypeAny
+}
+
+// NumberLiteralNode representa um número literal
+type NumberLiteralNode struct {
+	Span
+	Value int
+}
+
+func (n *NumberLiteralNode) GetType() Type {
+	return TypeNumber
+}
+
+// StringLiteralNode representa uma string literal
+type StringLiteralNode struct {
+	Span
+	Value string
+}
+
+func (n *StringLiteralNode) GetType() Type {
+	return TypeString
+}
+
+// BooleanLiteralNode representa um valor booleano literal
+type BooleanLiteralNode struct {
+	Span
+	Value bool
+}
+
+func (n *BooleanLiteralNode) GetType() Type {
+	return TypeBool
+}
+
+// InterpolatedStringNode representa uma string com interpolações 💱{...}
+type InterpolatedStringNode struct {
+	Span
+	Parts []Node // StringLiteralNode e InterpolationNode, na ordem
+}
+
+func (n *InterpolatedStringNode) GetType() Type {
+	return TypeString
+}
+
+// InterpolationNode representa uma expressão interpolada em uma string, com
+// especificação de formato opcional (💱{preco:.2f})
+type InterpolationNode struct {
+	Span
+	Expr   Node
+	Format string
+}
+
+func (n *InterpolationNode) GetType() Type {
+	return TypeString
+}
+
+// InputNode lê uma linha da entrada do programa, sem a quebra de linha. Se
+// houver um prompt, ele é escrito antes na saída, sem quebra de linha.
+type InputNode struct {
+	Span
+	Prompt Node // nil quando não há prompt
+}
+
+func (n *InputNode) GetType() Type {
+	return TypeString
+}
+
+// ConvertNode converte um valor para outro tipo: 🔢("42"), ⚖️("true"),
+// 📝(42). Textos que não representam um valor do tipo pedido lançam um erro
+// de execução.
+type ConvertNode struct {
+	Span
+	To    Type
+	Value Node
+}
+
+func (n *ConvertNode) GetType() Type {
+	return n.To
+}
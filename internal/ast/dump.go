@@ -0,0 +1,341 @@
+package ast
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"melhorzin-lang/internal/lexer"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Fprint escreve os nós em w como uma árvore indentada, um campo por linha,
+// com o trecho do código de cada nó em linha:coluna. source é o código de
+// onde os nós foram analisados.
+func Fprint(w io.Writer, source string, nodes []Node) error {
+	p := &treePrinter{lines: lexer.NewLineIndex(source)}
+	for _, node := range nodes {
+		Walk(p, node)
+	}
+	_, err := w.Write(p.out.Bytes())
+	return err
+}
+
+// MarshalJSON serializa os nós como um array JSON, em uma linha. Cada nó é
+// um objeto com "node" (o nome do tipo, como "PrintNode"), "span" e os
+// campos do nó em camelCase, na ordem em que são declarados.
+func MarshalJSON(source string, nodes []Node) ([]byte, error) {
+	b := &jsonBuilder{lines: lexer.NewLineIndex(source), result: make([]interface{}, 0, len(nodes))}
+	for _, node := range nodes {
+		Walk(b, node)
+	}
+	var out bytes.Buffer
+	encoder := json.NewEncoder(&out)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(b.result); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+// field é um campo de um nó na saída. value é um Node, um []Node ou um valor
+// simples (string, int, bool, Type, lexer.TokenType, []string, []Type).
+type field struct {
+	name  string
+	value interface{}
+}
+
+// fields retorna os campos de um nó que aparecem na saída, na ordem em que
+// são declarados. Os filhos (nós não nulos e itens de listas de nós) estão na
+// mesma ordem em que Walk os visita.
+func fields(node Node) []field {
+	switch n := node.(type) {
+	case *PrintNode:
+		return []field{{"Values", n.Values}}
+	case *AssignNode:
+		return []field{{"Name", n.Name}, {"Value", n.Value}, {"DeclaredType", n.DeclaredType}, {"InferredType", n.InferredType}}
+	case *EqualNode:
+		return []field{{"Left", n.Left}, {"Right", n.Right}}
+	case *BinaryOpNode:
+		return []field{{"Left", n.Left}, {"Op", n.Op}, {"Right", n.Right}}
+	case *LogicalNode:
+		return []field{{"Left", n.Left}, {"Op", n.Op}, {"Right", n.Right}}
+	case *UnaryOpNode:
+		return []field{{"Op", n.Op}, {"Operand", n.Operand}}
+	case *VariableNode:
+		return []field{{"Name", n.Name}, {"Type", n.Type}}
+	case *CompoundAssignNode:
+		return []field{{"Name", n.Name}, {"Op", n.Op}, {"Value", n.Value}, {"Type", n.Type}}
+	case *FunctionNode:
+		return []field{{"Name", n.Name}, {"Parameters", n.Parameters}, {"ParamTypes", n.ParamTypes}, {"ReturnType", n.ReturnType}, {"Body", n.Body}}
+	case *ReturnNode:
+		return []field{{"Value", n.Value}}
+	case *FunctionCallNode:
+		return []field{{"Name", n.Name}, {"Arguments", n.Arguments}}
+	case *MainNode:
+		return []field{{"Body", n.Body}}
+	case *TryCatchNode:
+		return []field{{"Label", n.Label}, {"Attempts", n.Attempts}, {"ErrorVar", n.ErrorVar}, {"TryBody", n.TryBody}, {"CatchBody", n.CatchBody}}
+	case *NumberLiteralNode:
+		return []field{{"Value", n.Value}}
+	case *StringLiteralNode:
+		return []field{{"Value", n.Value}}
+	case *BooleanLiteralNode:
+		return []field{{"Value", n.Value}}
+	case *InterpolatedStringNode:
+		return []field{{"Parts", n.Parts}}
+	case *InterpolationNode:
+		return []field{{"Expr", n.Expr}, {"Format", n.Format}}
+	case *InputNode:
+		return []field{{"Prompt", n.Prompt}}
+	case *ConvertNode:
+		return []field{{"To", n.To}, {"Value", n.Value}}
+	}
+	panic(fmt.Sprintf("fields: tipo de nó inesperado %T", node))
+}
+
+// children retorna quantos filhos um campo tem: 1 para um nó, o tamanho para
+// uma lista de nós e 0 para valores simples e nós nulos
+func (f field) children() int {
+	switch v := f.value.(type) {
+	case Node:
+		return 1
+	case []Node:
+		return len(v)
+	}
+	return 0
+}
+
+func nodeName(node Node) string {
+	return strings.TrimPrefix(fmt.Sprintf("%T", node), "*ast.")
+}
+
+// treePrinter é o Visitor de Fprint. Cada nó visitado escreve sua linha e
+// empilha seus campos; os campos simples são escritos à medida que Walk passa
+// pelos filhos, para que a saída siga a ordem de declaração.
+type treePrinter struct {
+	out   bytes.Buffer
+	lines *lexer.LineIndex
+	stack []*treeFrame
+}
+
+// treeFrame são os campos de um nó que ainda não foram escritos
+type treeFrame struct {
+	fields []field
+	next   int // Próximo campo
+	item   int // Próximo item do campo, em listas de nós
+}
+
+func (p *treePrinter) Visit(node Node) Visitor {
+	depth := len(p.stack)
+	if node == nil {
+		p.advance(p.stack[depth-1], depth)
+		p.stack = p.stack[:depth-1]
+		return nil
+	}
+
+	label := ""
+	if depth > 0 {
+		label = p.advance(p.stack[depth-1], depth)
+	}
+	p.printf(depth, "%s%s %s", label, nodeName(node), p.span(node.GetSpan()))
+	p.stack = append(p.stack, &treeFrame{fields: fields(node)})
+	return p
+}
+
+// advance escreve os campos de frame até o próximo filho e retorna o rótulo
+// dele ("Left: ", "Body[2]: "), ou "" se não há mais filhos
+func (p *treePrinter) advance(frame *treeFrame, depth int) string {
+	for ; frame.next < len(frame.fields); frame.next++ {
+		f := frame.fields[frame.next]
+		switch {
+		case f.children() == 0:
+			p.printf(depth, "%s: %s", f.name, scalar(f.value))
+		case frame.item < f.children():
+			label := f.name + ": "
+			if _, ok := f.value.([]Node); ok {
+				label = fmt.Sprintf("%s[%d]: ", f.name, frame.item)
+			}
+			frame.item++
+			if frame.item == f.children() {
+				frame.next, frame.item = frame.next+1, 0
+			}
+			return label
+		}
+	}
+	return ""
+}
+
+// span formata o trecho de um nó: 1:5-1:12
+func (p *treePrinter) span(s Span) string {
+	line, column := p.lines.Position(s.Pos)
+	endLine, endColumn := p.lines.Position(s.End)
+	return fmt.Sprintf("%d:%d-%d:%d", line, column, endLine, endColumn)
+}
+
+func (p *treePrinter) printf(depth int, format string, args ...interface{}) {
+	p.out.WriteString(strings.Repeat("  ", depth))
+	fmt.Fprintf(&p.out, format, args...)
+	p.out.WriteByte('\n')
+}
+
+// scalar formata um valor que não é filho: strings entre aspas, tipos e
+// tokens pelo nome, listas entre colchetes, nós nulos como nulo
+func scalar(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "nulo"
+	case string:
+		return strconv.Quote(v)
+	case []Node:
+		return "[]"
+	case []string:
+		items := make([]string, len(v))
+		for i, item := range v {
+			items[i] = strconv.Quote(item)
+		}
+		return "[" + strings.Join(items, ", ") + "]"
+	case []Type:
+		items := make([]string, len(v))
+		for i, item := range v {
+			items[i] = string(item)
+		}
+		return "[" + strings.Join(items, ", ") + "]"
+	}
+	return fmt.Sprint(value) // int, bool, Type, lexer.TokenType
+}
+
+// jsonBuilder é o Visitor de MarshalJSON. Cada nó visitado empilha seu
+// objeto, com os filhos ainda vazios; quando Walk termina o nó, o objeto é
+// colocado no campo do pai (ou no resultado, se não há pai).
+type jsonBuilder struct {
+	lines  *lexer.LineIndex
+	stack  []*jsonFrame
+	result []interface{}
+}
+
+// jsonFrame é o objeto de um nó em construção
+type jsonFrame struct {
+	object jsonObject
+	slots  []int // Índice em object do campo de cada filho, na ordem de Walk
+}
+
+func (b *jsonBuilder) Visit(node Node) Visitor {
+	if node != nil {
+		b.stack = append(b.stack, b.open(node))
+		return b
+	}
+
+	depth := len(b.stack)
+	object := b.stack[depth-1].object
+	b.stack = b.stack[:depth-1]
+	if depth == 1 {
+		b.result = append(b.result, object)
+		return nil
+	}
+	parent := b.stack[depth-2]
+	slot := &parent.object[parent.slots[0]]
+	parent.slots = parent.slots[1:]
+	if list, ok := slot.value.([]interface{}); ok {
+		slot.value = append(list, object)
+	} else {
+		slot.value = object
+	}
+	return nil
+}
+
+// open cria o objeto de um nó, com "node", "span" e os valores simples; os
+// campos com filhos ficam vazios até Walk visitá-los
+func (b *jsonBuilder) open(node Node) *jsonFrame {
+	span := node.GetSpan()
+	line, column := b.lines.Position(span.Pos)
+	endLine, endColumn := b.lines.Position(span.End)
+	frame := &jsonFrame{object: jsonObject{
+		{"node", nodeName(node)},
+		{"span", jsonObject{
+			{"pos", span.Pos}, {"end", span.End},
+			{"line", line}, {"column", column},
+			{"endLine", endLine}, {"endColumn", endColumn},
+		}},
+	}}
+	for _, f := range fields(node) {
+		value := jsonValue(f.value)
+		if _, ok := f.value.([]Node); ok {
+			value = make([]interface{}, 0, f.children())
+		}
+		for range f.children() {
+			frame.slots = append(frame.slots, len(frame.object))
+		}
+		frame.object = append(frame.object, jsonField{camelCase(f.name), value})
+	}
+	return frame
+}
+
+// jsonValue converte um valor que não é filho para o JSON: tipos e tokens
+// viram strings e nós nulos viram null
+func jsonValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case Node:
+		return nil // Preenchido quando o filho é visitado
+	case Type:
+		return string(v)
+	case lexer.TokenType:
+		return string(v)
+	case []Type:
+		items := make([]string, len(v))
+		for i, item := range v {
+			items[i] = string(item)
+		}
+		return items
+	}
+	return value
+}
+
+// jsonObject é um objeto JSON que mantém a ordem dos campos
+type jsonObject []jsonField
+
+type jsonField struct {
+	key   string
+	value interface{}
+}
+
+func (o jsonObject) MarshalJSON() ([]byte, error) {
+	var out bytes.Buffer
+	out.WriteByte('{')
+	for i, field := range o {
+		if i > 0 {
+			out.WriteByte(',')
+		}
+		key, _ := json.Marshal(field.key)
+		out.Write(key)
+		out.WriteByte(':')
+		value, err := marshalNoEscape(field.value)
+		if err != nil {
+			return nil, err
+		}
+		out.Write(value)
+	}
+	out.WriteByte('}')
+	return out.Bytes(), nil
+}
+
+// marshalNoEscape serializa value sem trocar <, > e & por escapes
+func marshalNoEscape(value interface{}) ([]byte, error) {
+	var out bytes.Buffer
+	encoder := json.NewEncoder(&out)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(value); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(out.Bytes(), []byte("\n")), nil
+}
+
+// camelCase converte o nome de um campo Go para o nome no JSON:
+// DeclaredType vira declaredType
+func camelCase(name string) string {
+	runes := []rune(name)
+	runes[0] = unicode.ToLower(runes[0])
+	return string(runes)
+}
@@ -0,0 +1,138 @@
+package ast_test
+
+import (
+	"encoding/json"
+	"melhorzin-lang/internal/ast"
+	"melhorzin-lang/internal/lexer"
+	"melhorzin-lang/internal/parser"
+	"strings"
+	"testing"
+)
+
+// everyNode é um programa com todos os tipos de nó
+const everyNode = `main ✍️ ✍️ {
+    ▶️ f(a: 🔢): 🔢 { ↩️ -a << 1 }
+    ✍️ x = f(1) ✖️ 2
+    x += 1
+    ✍️ s = 📝(⌨️("n <&> "))
+    👨🏿‍💻 { 🖨️ "v 💱{x:>3}", x 🟰 1 🤝 🚫 true } 🤦🏿‍♂️ { 🖨️ erro }
+}`
+
+// nodeTypes são os nomes de todos os tipos de nó
+var nodeTypes = []string{
+	"AssignNode", "BinaryOpNode", "BooleanLiteralNode", "CompoundAssignNode",
+	"ConvertNode", "EqualNode", "FunctionCallNode", "FunctionNode", "InputNode",
+	"InterpolatedStringNode", "InterpolationNode", "LogicalNode", "MainNode",
+	"NumberLiteralNode", "PrintNode", "ReturnNode", "StringLiteralNode",
+	"TryCatchNode", "UnaryOpNode", "VariableNode",
+}
+
+func parse(t *testing.T, source string) []ast.Node {
+	t.Helper()
+	lex := lexer.NewLexer(source)
+	nodes := parser.NewParser(lex).Parse()
+	if errs := lex.Errors(); len(errs) > 0 {
+		t.Fatalf("erro léxico em %q: %v", source, errs[0])
+	}
+	return nodes
+}
+
+func TestFprint(t *testing.T) {
+	source := "✍️ x:🔢 = 1 + 2\n🖨️ \"a💱{x}\", x"
+	want := `AssignNode 1:1-1:15
+  Name: "x"
+  Value: BinaryOpNode 1:10-1:15
+    Left: NumberLiteralNode 1:10-1:11
+      Value: 1
+    Op: PLUS
+    Right: NumberLiteralNode 1:14-1:15
+      Value: 2
+  DeclaredType: NUMBER
+  InferredType: NUMBER
+PrintNode 2:1-2:14
+  Values[0]: InterpolatedStringNode 2:4-2:11
+    Parts[0]: StringLiteralNode 2:4-2:6
+      Value: "a"
+    Parts[1]: InterpolationNode 2:6-2:10
+      Expr: VariableNode 2:8-2:9
+        Name: "x"
+        Type: NUMBER
+      Format: ""
+  Values[1]: VariableNode 2:13-2:14
+    Name: "x"
+    Type: NUMBER
+`
+	var out strings.Builder
+	if err := ast.Fprint(&out, source, parse(t, source)); err != nil {
+		t.Fatal(err)
+	}
+	if out.String() != want {
+		t.Errorf("Fprint =\n%s\nesperado\n%s", out.String(), want)
+	}
+}
+
+// Todo tipo de nó aparece na árvore indentada
+func TestFprintEveryNode(t *testing.T) {
+	var out strings.Builder
+	if err := ast.Fprint(&out, everyNode, parse(t, everyNode)); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range nodeTypes {
+		if !strings.Contains(out.String(), name+" ") {
+			t.Errorf("Fprint não mostrou %s:\n%s", name, out.String())
+		}
+	}
+}
+
+func TestMarshalJSON(t *testing.T) {
+	nodes := parse(t, everyNode)
+	data, err := ast.MarshalJSON(everyNode, nodes)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A serialização é estável e não escapa < > &
+	again, err := ast.MarshalJSON(everyNode, nodes)
+	if err != nil || string(again) != string(data) {
+		t.Errorf("serializar de novo mudou o JSON:\n%s\n%s", data, again)
+	}
+	if !strings.Contains(string(data), `"value":"n <&> "`) {
+		t.Errorf("JSON com < escapado: %s", data)
+	}
+
+	var decoded []interface{}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("JSON inválido: %v\n%s", err, data)
+	}
+	seen := map[string]bool{}
+	collectNodes(t, decoded, seen)
+	for _, name := range nodeTypes {
+		if !seen[name] {
+			t.Errorf("o JSON não tem nenhum %s", name)
+		}
+	}
+}
+
+// collectNodes marca em seen o nome de cada nó do JSON decodificado e
+// verifica que todos têm a posição
+func collectNodes(t *testing.T, value interface{}, seen map[string]bool) {
+	switch v := value.(type) {
+	case []interface{}:
+		for _, item := range v {
+			collectNodes(t, item, seen)
+		}
+	case map[string]interface{}:
+		if name, ok := v["node"].(string); ok {
+			seen[name] = true
+			span, _ := v["span"].(map[string]interface{})
+			for _, key := range []string{"pos", "end", "line", "column", "endLine", "endColumn"} {
+				if _, ok := span[key]; !ok {
+					t.Errorf("%s sem %s no span: %v", name, key, v["span"])
+				}
+			}
+		}
+		for _, field := range v {
+			collectNodes(t, field, seen)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package ast
+
+import "fmt"
+
+// Visitor é chamado por Walk para cada nó da AST. Se Visit retornar um
+// Visitor w diferente de nil, Walk visita os filhos do nó com w e depois
+// chama w.Visit(nil).
+type Visitor interface {
+	Visit(node Node) (w Visitor)
+}
+
+// Walk percorre a AST em profundidade, a partir de node: chama v.Visit(node)
+// e, se o resultado w não for nil, visita cada filho com w e termina com
+// w.Visit(nil). Os filhos são visitados na ordem em que aparecem no código.
+func Walk(v Visitor, node Node) {
+	if v = v.Visit(node); v == nil {
+		return
+	}
+
+	switch n := node.(type) {
+	case *PrintNode:
+		walkList(v, n.Values)
+	case *AssignNode:
+		Walk(v, n.Value)
+	case *EqualNode:
+		Walk(v, n.Left)
+		Walk(v, n.Right)
+	case *BinaryOpNode:
+		Walk(v, n.Left)
+		Walk(v, n.Right)
+	case *LogicalNode:
+		Walk(v, n.Left)
+		Walk(v, n.Right)
+	case *UnaryOpNode:
+		Walk(v, n.Operand)
+	case *CompoundAssignNode:
+		if n.Value != nil {
+			Walk(v, n.Value)
+		}
+	case *FunctionNode:
+		walkList(v, n.Body)
+	case *ReturnNode:
+		Walk(v, n.Value)
+	case *FunctionCallNode:
+		walkList(v, n.Arguments)
+	case *MainNode:
+		walkList(v, n.Body)
+	case *TryCatchNode:
+		walkList(v, n.TryBody)
+		walkList(v, n.CatchBody)
+	case *InterpolatedStringNode:
+		walkList(v, n.Parts)
+	case *InterpolationNode:
+		Walk(v, n.Expr)
+	case *InputNode:
+		if n.Prompt != nil {
+			Walk(v, n.Prompt)
+		}
+	case *ConvertNode:
+		Walk(v, n.Value)
+	case *VariableNode, *NumberLiteralNode, *StringLiteralNode, *BooleanLiteralNode:
+		// Nós sem filhos
+	default:
+		panic(fmt.Sprintf("Walk: tipo de nó inesperado %T", node))
+	}
+
+	v.Visit(nil)
+}
+
+func walkList(v Visitor, nodes []Node) {
+	for _, node := range nodes {
+		Walk(v, node)
+	}
+}
+
+// inspector adapta uma função a Visitor
+type inspector func(Node) bool
+
+func (f inspector) Visit(node Node) Visitor {
+	if f(node) {
+		return f
+	}
+	return nil
+}
+
+// Inspect percorre a AST em profundidade, a partir de node, chamando
+// f(node). Se f retornar true, Inspect visita os filhos do nó e depois chama
+// f(nil).
+//
+//	ast.Inspect(node, func(n ast.Node) bool {
+//		if call, ok := n.(*ast.FunctionCallNode); ok {
+//			fmt.Println(call.Name)
+//		}
+//		return true
+//	})
+func Inspect(node Node, f func(Node) bool) {
+	Walk(inspector(f), node)
+}
+
+// InspectAll chama Inspect para cada nó de um programa, na ordem.
+func InspectAll(nodes []Node, f func(Node) bool) {
+	for _, node := range nodes {
+		Inspect(node, f)
+	}
+}
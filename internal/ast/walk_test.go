@@ -0,0 +1,113 @@
+package ast_test
+
+import (
+	"fmt"
+	"melhorzin-lang/internal/ast"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// Walk visita todos os tipos de nó, na ordem do código
+func TestWalkEveryNode(t *testing.T) {
+	nodes := parse(t, everyNode)
+	seen := map[string]bool{}
+	last := -1
+	ast.InspectAll(nodes, func(n ast.Node) bool {
+		if n == nil {
+			return false
+		}
+		name := strings.TrimPrefix(fmt.Sprintf("%T", n), "*ast.")
+		seen[name] = true
+		if pos := n.GetSpan().Pos; pos < last {
+			t.Errorf("%s em %d visitado depois da posição %d", name, pos, last)
+		} else {
+			last = pos
+		}
+		return true
+	})
+	for _, name := range nodeTypes {
+		if !seen[name] {
+			t.Errorf("Walk não visitou nenhum %s", name)
+		}
+	}
+}
+
+// order registra a ordem das visitas: o nome do nó ao entrar e "fim" na
+// chamada Visit(nil) depois dos filhos
+type order struct {
+	visits *[]string
+	skip   string // Tipo de nó cujos filhos não são visitados
+}
+
+func (o order) Visit(node ast.Node) ast.Visitor {
+	if node == nil {
+		*o.visits = append(*o.visits, "fim")
+		return nil
+	}
+	name := strings.TrimPrefix(fmt.Sprintf("%T", node), "*ast.")
+	*o.visits = append(*o.visits, name)
+	if name == o.skip {
+		return nil
+	}
+	return o
+}
+
+func TestWalkOrder(t *testing.T) {
+	nodes := parse(t, "🖨️ 1 + 2, f(x)")
+	tests := []struct {
+		skip string
+		want []string
+	}{
+		{"", []string{
+			"PrintNode",
+			"BinaryOpNode", "NumberLiteralNode", "fim", "NumberLiteralNode", "fim", "fim",
+			"FunctionCallNode", "VariableNode", "fim", "fim",
+			"fim",
+		}},
+		{"BinaryOpNode", []string{
+			"PrintNode",
+			"BinaryOpNode",
+			"FunctionCallNode", "VariableNode", "fim", "fim",
+			"fim",
+		}},
+		{"PrintNode", []string{"PrintNode"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.skip, func(t *testing.T) {
+			var visits []string
+			ast.Walk(order{visits: &visits, skip: tt.skip}, nodes[0])
+			if !slices.Equal(visits, tt.want) {
+				t.Errorf("visitas = %v, esperado %v", visits, tt.want)
+			}
+		})
+	}
+}
+
+// Inspect com f retornando false não desce nos filhos; InspectAll percorre
+// cada instrução
+func TestInspect(t *testing.T) {
+	nodes := parse(t, "▶️ f(a) { ↩️ g(a) }\n🖨️ h(1)")
+	var calls []string
+	ast.InspectAll(nodes, func(n ast.Node) bool {
+		if call, ok := n.(*ast.FunctionCallNode); ok {
+			calls = append(calls, call.Name)
+		}
+		_, function := n.(*ast.FunctionNode)
+		return !function
+	})
+	if !slices.Equal(calls, []string{"h"}) {
+		t.Errorf("chamadas fora de funções = %v, esperado [h]", calls)
+	}
+
+	calls = nil
+	ast.InspectAll(nodes, func(n ast.Node) bool {
+		if call, ok := n.(*ast.FunctionCallNode); ok {
+			calls = append(calls, call.Name)
+		}
+		return true
+	})
+	if !slices.Equal(calls, []string{"g", "h"}) {
+		t.Errorf("todas as chamadas = %v, esperado [g h]", calls)
+	}
+}
@@ -0,0 +1,101 @@
+package convert
+
+import (
+	"fmt"
+	"io"
+	"melhorzin-lang/internal/lexer"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Surface é uma das superfícies de sintaxe da linguagem.
+type Surface string
+
+const (
+	Emoji Surface = "emoji" // 🖨️, ✍️, ▶️, ...
+	Text  Surface = "text"  // print, let, fn, ...
+)
+
+// ParseSurface converte o nome de uma superfície ("emoji" ou "text").
+func ParseSurface(name string) (Surface, error) {
+	switch Surface(name) {
+	case Emoji, Text:
+		return Surface(name), nil
+	default:
+		return "", fmt.Errorf("superfície desconhecida %q (use emoji ou text)", name)
+	}
+}
+
+// Convert reescreve as palavras-chave de src na superfície indicada. Apenas
+// os tokens de palavras-chave são trocados: espaços, comentários, strings e
+// identificadores são preservados byte a byte, e o resultado produz os mesmos
+// tokens que o original.
+//
+// Para converter em emoji, src é lido com a superfície textual
+// (lexer.TextKeywords); para converter em texto, com a tabela padrão. Neste
+// caso um identificador que é palavra-chave textual (um ✍️ print = 1) viraria
+// outro token, e Convert retorna um erro em vez de mudar o programa.
+func Convert(src string, to Surface) (string, error) {
+	form := lexer.EmojiForm
+	keywords := lexer.TextKeywords()
+	if to == Text {
+		form = lexer.TextForm
+		keywords = lexer.DefaultKeywords()
+	}
+
+	lex := lexer.NewLexerWithKeywords(src, keywords)
+	lex.SetErrorOutput(io.Discard)
+	tokens := lex.Lex()
+	if to == Text {
+		for _, tok := range tokens {
+			if tok.Type == lexer.TokenIdentifier && lexer.IsTextKeyword(tok.Value) {
+				line, column := lexer.NewLineIndex(src).Position(tok.Pos)
+				return "", fmt.Errorf("o identificador %s (linha %d, coluna %d) é uma palavra-chave na sintaxe textual", tok.Value, line, column)
+			}
+		}
+	}
+
+	var out strings.Builder
+	last := 0
+	for _, tok := range tokens {
+		replacement, ok := form(tok.Type)
+		if !ok || replacement == tok.Value {
+			continue
+		}
+		// Só reescreve tokens cuja posição corresponde ao texto original
+		// (tokens do corpo de strings com três aspas têm posição aproximada)
+		end := tok.Pos + len(tok.Value)
+		if tok.Pos < last || end > len(src) || src[tok.Pos:end] != tok.Value {
+			continue
+		}
+
+		out.WriteString(src[last:tok.Pos])
+		// Palavras precisam de espaço para não se juntar a identificadores
+		// vizinhos: ✍️x vira let x, e não letx
+		if before, _ := utf8.DecodeLastRuneInString(src[:tok.Pos]); isWordRune(before) && startsWithWord(replacement) {
+			out.WriteByte(' ')
+		}
+		out.WriteString(replacement)
+		if after, _ := utf8.DecodeRuneInString(src[end:]); isWordRune(after) && endsWithWord(replacement) {
+			out.WriteByte(' ')
+		}
+		last = end
+	}
+	out.WriteString(src[last:])
+	return out.String(), nil
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_'
+}
+
+func startsWithWord(s string) bool {
+	r, _ := utf8.DecodeRuneInString(s)
+	return isWordRune(r)
+}
+
+func endsWithWord(s string) bool {
+	r, _ := utf8.DecodeLastRuneInString(s)
+	return isWordRune(r)
+}
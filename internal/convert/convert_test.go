@@ -0,0 +1,92 @@
+package convert
+
+import (
+	"io"
+	"melhorzin-lang/internal/lexer"
+	"strings"
+	"testing"
+)
+
+func TestConvert(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		to   Surface
+		want string
+		err  string // Trecho da mensagem de erro esperada; vazio se não há erro
+	}{
+		{"emoji para texto", "✍️ x = 1\n🖨️ x", Text, "let x = 1\nprint x", ""},
+		{"operadores", "🖨️ a 🟰 b 🤝 🚫 c 🔀 d", Text, "print a == b and not c or d", ""},
+		{"função", "▶️ f(a: 🔢): 📝 { ↩️ 📝(a) }", Text, "fn f(a: num): str { return str(a) }", ""},
+		{"multiplicação", "🖨️ 2 ✖️ 3", Text, "print 2 * 3", ""},
+		{"➕ não tem forma textual", "🖨️ 2 ➕ 3", Text, "print 2 ➕ 3", ""},
+		{"espaço entre palavras", "✍️x = 1", Text, "let x = 1", ""},
+		{"comentários e strings intactos", "🖨️ \"🖨️ print\" // ✍️ let", Text, "print \"🖨️ print\" // ✍️ let", ""},
+		{"identificador textual", "✍️ print = 1", Text, "", "print (linha 1, coluna 4)"},
+		{"texto para emoji", "let x = 1\nprint x * 2", Emoji, "✍️ x = 1\n🖨️ x ✖️ 2", ""},
+		{"try/catch", "try { print 1 } catch e { print e }", Emoji, "👨🏿‍💻 { 🖨️ 1 } 🤦🏿‍♂️ e { 🖨️ e }", ""},
+		{"emoji já convertido", "🖨️ 1", Emoji, "🖨️ 1", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Convert(tt.src, tt.to)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("Convert(%q) = %q, %v; esperado erro com %q", tt.src, got, err, tt.err)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("Convert(%q, %s) = %q, %v; esperado %q", tt.src, tt.to, got, err, tt.want)
+			}
+		})
+	}
+}
+
+// Converter para uma superfície e de volta produz os mesmos tokens
+func TestConvertRoundTrip(t *testing.T) {
+	sources := []string{
+		"✍️ x: 🔢 = 0x10 ✖️ 2 ➕ 1\nx += 3\n🖨️ \"💱{x:>4}\", x 🟰 35",
+		"🚀 bloco, 3 👨🏿‍💻 { 🖨️ ⌨️(\"nome: \") } 🤦🏿‍♂️ erro { 🖨️ erro }",
+		"main ✍️ ✍️ { 🖨️ 🚫 true 🔀 false }",
+	}
+	for _, src := range sources {
+		t.Run(src, func(t *testing.T) {
+			text, err := Convert(src, Text)
+			if err != nil {
+				t.Fatal(err)
+			}
+			emoji, err := Convert(text, Emoji)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := tokenTypes(src, lexer.DefaultKeywords())
+			for _, got := range [][]string{tokenTypes(text, lexer.TextKeywords()), tokenTypes(emoji, lexer.DefaultKeywords())} {
+				if strings.Join(got, " ") != strings.Join(want, " ") {
+					t.Errorf("tokens mudaram:\n%v\nesperado\n%v", got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestParseSurface(t *testing.T) {
+	for _, name := range []string{"emoji", "text"} {
+		if surface, err := ParseSurface(name); err != nil || string(surface) != name {
+			t.Errorf("ParseSurface(%q) = %q, %v", name, surface, err)
+		}
+	}
+	if _, err := ParseSurface("klingon"); err == nil {
+		t.Error("ParseSurface(klingon): esperado erro")
+	}
+}
+
+func tokenTypes(src string, keywords *lexer.Keywords) []string {
+	lex := lexer.NewLexerWithKeywords(src, keywords)
+	lex.SetErrorOutput(io.Discard)
+	var types []string
+	for _, token := range lex.Lex() {
+		types = append(types, string(token.Type))
+	}
+	return types
+}
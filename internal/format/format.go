@@ -0,0 +1,451 @@
+// Package format escreve programas emojilang no layout canônico: uma
+// instrução por linha, blocos indentados com quatro espaços, espaços em volta
+// dos operadores binários e as palavras-chave na grafia canônica, com o
+// variation selector. A formatação parte da AST, e os comentários e linhas em
+// branco entre instruções são recuperados do código fonte.
+package format
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"melhorzin-lang/internal/ast"
+	"melhorzin-lang/internal/convert"
+	"melhorzin-lang/internal/lexer"
+	"strconv"
+	"strings"
+)
+
+// indentation é a indentação de cada nível de bloco
+const indentation = "    "
+
+// Config configura a formatação. O valor zero formata programas analisados
+// com a tabela padrão de palavras-chave.
+type Config struct {
+	// Keywords é o dicionário com que o código foi analisado, por exemplo
+	// lexer.TextKeywords() para programas na sintaxe textual. Se nil, é
+	// lexer.DefaultKeywords().
+	Keywords *lexer.Keywords
+}
+
+// Fprint escreve em w os nós no layout canônico com a configuração padrão
+// (veja Config.Fprint).
+func Fprint(w io.Writer, source string, nodes []ast.Node) error {
+	return (&Config{}).Fprint(w, source, nodes)
+}
+
+// Source formata um programa já analisado com a configuração padrão e
+// retorna o código formatado.
+func Source(source string, nodes []ast.Node) (string, error) {
+	return (&Config{}).Source(source, nodes)
+}
+
+// Fprint escreve em w os nós no layout canônico. source é o código de onde os
+// nós foram analisados: dele vêm os comentários, as linhas em branco (no
+// máximo uma seguida é mantida) e o texto de strings e números, que ficam
+// como foram escritos. As palavras-chave são escritas na superfície (emoji ou
+// texto) usada pela maior parte do programa; os operadores, como foram
+// escritos.
+//
+// Formatar o resultado de novo não muda nada. Retorna um erro se o código
+// tiver tokens que o parser ignorou, que se perderiam na formatação.
+func (c *Config) Fprint(w io.Writer, source string, nodes []ast.Node) error {
+	keywords := c.Keywords
+	if keywords == nil {
+		keywords = lexer.DefaultKeywords()
+	}
+	tokens, comments, units := scan(source, keywords)
+	if err := checkIgnored(tokens, units, nodes); err != nil {
+		return err
+	}
+
+	p := &printer{
+		source:  source,
+		tokens:  tokens,
+		pending: comments,
+		surface: detectSurface(tokens),
+		last:    -1,
+	}
+	for _, node := range nodes {
+		p.statement(node)
+	}
+	p.comments(len(source) + 1)
+
+	_, err := w.Write(p.out.Bytes())
+	return err
+}
+
+// Source formata um programa já analisado e retorna o código formatado.
+func (c *Config) Source(source string, nodes []ast.Node) (string, error) {
+	var out strings.Builder
+	if err := c.Fprint(&out, source, nodes); err != nil {
+		return "", err
+	}
+	return out.String(), nil
+}
+
+type printer struct {
+	source  string
+	tokens  []lexer.Token
+	pending []comment // Comentários ainda não escritos, em ordem
+	surface convert.Surface
+	out     bytes.Buffer
+	indent  int
+	last    int // Fim, no código, do último item escrito; -1 no início de um bloco
+}
+
+func (p *printer) write(texts ...string) {
+	for _, text := range texts {
+		p.out.WriteString(text)
+	}
+}
+
+// line começa a linha de um item que está em pos no código, mantendo uma
+// linha em branco antes dele se havia alguma no código
+func (p *printer) line(pos int) {
+	if p.last >= 0 && pos > p.last && strings.Count(p.source[p.last:pos], "\n") >= 2 {
+		p.write("\n")
+	}
+	p.write(strings.Repeat(indentation, p.indent))
+}
+
+// newline termina a linha de um item que termina em end no código
+func (p *printer) newline(end int) {
+	p.write("\n")
+	p.last = max(p.last, end)
+}
+
+// keyword retorna a grafia de uma palavra-chave ou operador na superfície do
+// programa
+func (p *printer) keyword(typ lexer.TokenType) string {
+	form := lexer.EmojiForm
+	if p.surface == convert.Text {
+		form = lexer.TextForm
+	}
+	if word, ok := form(typ); ok {
+		return word
+	}
+	return operators[typ]
+}
+
+// operator retorna a grafia do operador typ que segue left no código. Os
+// operadores ficam como foram escritos (* continua *, ✖️ continua ✖️), só com
+// o variation selector normalizado (✖ vira ✖️).
+func (p *printer) operator(typ lexer.TokenType, left ast.Node) string {
+	token := p.find(typ, left.GetSpan().End)
+	if emoji, ok := lexer.EmojiForm(typ); ok && withoutVS16(token.Value) == withoutVS16(emoji) {
+		return emoji
+	}
+	if word, ok := operators[typ]; ok {
+		return word
+	}
+	return token.Value
+}
+
+// withoutVS16 remove os variation selectors de s
+func withoutVS16(s string) string {
+	return strings.ReplaceAll(s, "\uFE0F", "")
+}
+
+// operators é a grafia dos tokens que não têm forma em emoji e em texto
+var operators = map[lexer.TokenType]string{
+	lexer.TokenMain:         "main",
+	lexer.TokenEqualSign:    "=",
+	lexer.TokenTypeColon:    ":",
+	lexer.TokenPlus:         "+",
+	lexer.TokenMinus:        "-",
+	lexer.TokenNumPlus:      "➕",
+	lexer.TokenConcat:       ".",
+	lexer.TokenBitAnd:       "&",
+	lexer.TokenBitOr:        "|",
+	lexer.TokenBitXor:       "^",
+	lexer.TokenShiftLeft:    "<<",
+	lexer.TokenShiftRight:   ">>",
+	lexer.TokenPlusAssign:   "+=",
+	lexer.TokenMinusAssign:  "-=",
+	lexer.TokenMultAssign:   "*=",
+	lexer.TokenConcatAssign: ".=",
+	lexer.TokenIncrement:    "++",
+	lexer.TokenDecrement:    "--",
+}
+
+// typeTokens associa cada tipo à palavra-chave que o anota
+var typeTokens = map[ast.Type]lexer.TokenType{
+	ast.TypeNumber: lexer.TokenTypeNumber,
+	ast.TypeString: lexer.TokenTypeString,
+	ast.TypeBool:   lexer.TokenTypeBool,
+	ast.TypeAny:    lexer.TokenTypeAny,
+}
+
+// annotation retorna a anotação de tipo :T, ou "" se o tipo não foi anotado
+func (p *printer) annotation(typ ast.Type, explicit bool) string {
+	if typ == ast.TypeAny && !explicit {
+		return ""
+	}
+	return p.keyword(lexer.TokenTypeColon) + p.keyword(typeTokens[typ])
+}
+
+// statement escreve uma instrução em sua própria linha, precedida dos
+// comentários que vêm antes dela e seguida do comentário na mesma linha
+func (p *printer) statement(node ast.Node) {
+	span := node.GetSpan()
+	switch node.(type) {
+	case *ast.FunctionNode, *ast.MainNode, *ast.TryCatchNode:
+		// Comentários dentro do bloco são escritos no corpo
+		p.comments(span.Pos)
+	default:
+		// Comentários no meio de uma instrução vão para antes dela
+		p.comments(span.End)
+	}
+	p.line(span.Pos)
+
+	switch n := node.(type) {
+	case *ast.PrintNode:
+		p.write(p.keyword(lexer.TokenPrint), " ")
+		p.exprList(n.Values)
+	case *ast.AssignNode:
+		// ✍️ x:🗑️ = ... e ✍️ x = ... produzem o mesmo nó
+		explicit := p.tokens[p.tokenAt(n.Pos)+2].Type == lexer.TokenTypeColon
+		p.write(p.keyword(lexer.TokenAssign), " ", n.Name, p.annotation(n.DeclaredType, explicit), " = ")
+		p.expr(n.Value)
+	case *ast.CompoundAssignNode:
+		p.write(n.Name)
+		if n.Value == nil {
+			p.write(p.keyword(n.Op))
+		} else {
+			p.write(" ", p.keyword(n.Op), " ")
+			p.expr(n.Value)
+		}
+	case *ast.ReturnNode:
+		p.write(p.keyword(lexer.TokenReturn), " ")
+		p.expr(n.Value)
+	case *ast.FunctionNode:
+		p.function(n)
+	case *ast.MainNode:
+		assign := p.keyword(lexer.TokenAssign)
+		p.write(p.keyword(lexer.TokenMain), " ", assign, " ", assign)
+		p.block(p.find(lexer.TokenLBrace, n.Pos), n.Body, span.End-1)
+	case *ast.TryCatchNode:
+		p.tryCatch(n)
+	default:
+		p.expr(node)
+	}
+
+	p.trailing(span.End)
+	p.newline(span.End)
+}
+
+// block escreve um bloco entre chaves: open é a { no código e close a
+// posição da }
+func (p *printer) block(open lexer.Token, body []ast.Node, close int) {
+	p.write(" {")
+	p.trailing(open.End)
+	p.newline(open.End)
+
+	p.indent++
+	p.last = -1
+	for _, node := range body {
+		p.statement(node)
+	}
+	p.comments(close)
+	p.indent--
+
+	p.write(strings.Repeat(indentation, p.indent), "}")
+	p.last = close + 1
+}
+
+func (p *printer) function(n *ast.FunctionNode) {
+	// Como em ✍️, um parâmetro ou retorno anotado com 🗑️ não se distingue
+	// de um sem anotação na AST
+	params := make([]bool, len(n.Parameters))
+	i := p.tokenAt(n.Pos)
+	for p.tokens[i].Type != lexer.TokenLParen {
+		i++
+	}
+	param := -1
+	for i++; p.tokens[i].Type != lexer.TokenRParen; i++ {
+		switch p.tokens[i].Type {
+		case lexer.TokenIdentifier:
+			param++
+		case lexer.TokenTypeColon:
+			params[param] = true
+		}
+	}
+	result := p.tokens[i+1].Type == lexer.TokenTypeColon
+
+	p.write(p.keyword(lexer.TokenFunction), " ", n.Name, "(")
+	for j, name := range n.Parameters {
+		if j > 0 {
+			p.write(", ")
+		}
+		p.write(name, p.annotation(n.ParamTypes[j], params[j]))
+	}
+	p.write(")", p.annotation(n.ReturnType, result))
+	p.block(p.find(lexer.TokenLBrace, p.tokens[i].End), n.Body, n.End-1)
+}
+
+func (p *printer) tryCatch(n *ast.TryCatchNode) {
+	if n.Label != "" {
+		p.write(p.keyword(lexer.TokenTryStart), " ", n.Label, ", ", strconv.Itoa(n.Attempts), " ")
+	}
+	p.write(p.keyword(lexer.TokenTry))
+	open := p.find(lexer.TokenLBrace, n.Pos)
+	after := open.End
+	if len(n.TryBody) > 0 {
+		after = n.TryBody[len(n.TryBody)-1].GetSpan().End
+	}
+	catch := p.tokenAt(p.find(lexer.TokenCatch, after).Pos)
+	p.block(open, n.TryBody, p.tokens[catch-1].Pos)
+
+	p.write(" ", p.keyword(lexer.TokenCatch))
+	if p.tokens[catch+1].Type == lexer.TokenIdentifier {
+		p.write(" ", n.ErrorVar)
+	}
+	p.block(p.find(lexer.TokenLBrace, p.tokens[catch].End), n.CatchBody, n.End-1)
+}
+
+func (p *printer) exprList(nodes []ast.Node) {
+	for i, node := range nodes {
+		if i > 0 {
+			p.write(", ")
+		}
+		p.expr(node)
+	}
+}
+
+// Precedência dos operadores, da menor para a maior, como no parser
+const (
+	precOr = iota + 1
+	precAnd
+	precNot
+	precEqual
+	precBitOr
+	precBitXor
+	precBitAnd
+	precShift
+	precConcat
+	precAdditive
+	precMultiplicative
+	precUnary
+	precTerm
+)
+
+var binaryPrecedence = map[lexer.TokenType]int{
+	lexer.TokenBitOr:      precBitOr,
+	lexer.TokenBitXor:     precBitXor,
+	lexer.TokenBitAnd:     precBitAnd,
+	lexer.TokenShiftLeft:  precShift,
+	lexer.TokenShiftRight: precShift,
+	lexer.TokenConcat:     precConcat,
+	lexer.TokenPlus:       precAdditive,
+	lexer.TokenMinus:      precAdditive,
+	lexer.TokenNumPlus:    precAdditive,
+	lexer.TokenMult:       precMultiplicative,
+}
+
+func precedence(node ast.Node) int {
+	switch n := node.(type) {
+	case *ast.LogicalNode:
+		if n.Op == lexer.TokenOr {
+			return precOr
+		}
+		return precAnd
+	case *ast.UnaryOpNode:
+		if n.Op == lexer.TokenNot {
+			return precNot
+		}
+		return precUnary
+	case *ast.EqualNode:
+		return precEqual
+	case *ast.BinaryOpNode:
+		return binaryPrecedence[n.Op]
+	}
+	return precTerm
+}
+
+// expr escreve uma expressão, com parênteses só onde a precedência exige
+func (p *printer) expr(node ast.Node) {
+	switch n := node.(type) {
+	case *ast.LogicalNode:
+		p.binary(n.Left, p.keyword(n.Op), n.Right, precedence(n))
+	case *ast.EqualNode:
+		p.binary(n.Left, p.operator(lexer.TokenEqual, n.Left), n.Right, precEqual)
+	case *ast.BinaryOpNode:
+		p.binary(n.Left, p.operator(n.Op, n.Left), n.Right, precedence(n))
+	case *ast.UnaryOpNode:
+		if n.Op == lexer.TokenNot {
+			p.write(p.keyword(n.Op), " ")
+			p.operand(n.Operand, precedence(n.Operand) < precNot)
+			return
+		}
+		// Sinais seguidos não podem se juntar: - -x seria lido como --
+		p.write(p.keyword(n.Op))
+		p.operand(n.Operand, precedence(n.Operand) < precUnary || p.signed(n.Operand))
+	case *ast.VariableNode:
+		p.write(n.Name)
+	case *ast.NumberLiteralNode:
+		p.write(p.number(n))
+	case *ast.StringLiteralNode, *ast.InterpolatedStringNode:
+		// O texto da string é mantido como foi escrito: aspas, escapes,
+		// strings raw e com três aspas
+		span := n.GetSpan()
+		p.write(p.source[span.Pos:span.End])
+	case *ast.BooleanLiteralNode:
+		p.write(strconv.FormatBool(n.Value))
+	case *ast.FunctionCallNode:
+		p.write(n.Name, "(")
+		p.exprList(n.Arguments)
+		p.write(")")
+	case *ast.InputNode:
+		p.write(p.keyword(lexer.TokenInput), "(")
+		if n.Prompt != nil {
+			p.expr(n.Prompt)
+		}
+		p.write(")")
+	case *ast.ConvertNode:
+		p.write(p.keyword(typeTokens[n.To]), "(")
+		p.expr(n.Value)
+		p.write(")")
+	default:
+		panic(fmt.Sprintf("format: tipo de nó inesperado %T", node))
+	}
+}
+
+// binary escreve uma operação binária associativa à esquerda com
+// precedência prec
+func (p *printer) binary(left ast.Node, op string, right ast.Node, prec int) {
+	p.operand(left, precedence(left) < prec)
+	p.write(" ", op, " ")
+	p.operand(right, precedence(right) <= prec)
+}
+
+func (p *printer) operand(node ast.Node, parens bool) {
+	if parens {
+		p.write("(")
+	}
+	p.expr(node)
+	if parens {
+		p.write(")")
+	}
+}
+
+// signed indica se a expressão começa com um sinal
+func (p *printer) signed(node ast.Node) bool {
+	switch n := node.(type) {
+	case *ast.UnaryOpNode:
+		return n.Op != lexer.TokenNot
+	case *ast.NumberLiteralNode:
+		return strings.HasPrefix(p.number(n), "-")
+	}
+	return false
+}
+
+// number retorna o texto de um número como foi escrito (0xFF, 1_000). Um
+// literal negativo começa no sinal, que é juntado ao número.
+func (p *printer) number(n *ast.NumberLiteralNode) string {
+	token := p.tokens[p.tokenAt(n.End)-1]
+	if n.Pos < token.Pos {
+		return "-" + token.Value
+	}
+	return token.Value
+}
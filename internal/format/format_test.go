@@ -0,0 +1,121 @@
+package format
+
+import (
+	"io"
+	"melhorzin-lang/internal/ast"
+	"melhorzin-lang/internal/lexer"
+	"melhorzin-lang/internal/parser"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// formatString analisa e formata source com as palavras-chave de keywords
+func formatString(t *testing.T, source string, keywords *lexer.Keywords) string {
+	t.Helper()
+	lex := lexer.NewLexerWithKeywords(source, keywords)
+	lex.SetErrorOutput(io.Discard)
+	nodes := parser.NewParser(lex).Parse()
+	if errs := lex.Errors(); len(errs) > 0 {
+		t.Fatalf("erro léxico em %q: %v", source, errs[0])
+	}
+	out, err := (&Config{Keywords: keywords}).Source(source, nodes)
+	if err != nil {
+		t.Fatalf("Source(%q): %v", source, err)
+	}
+	return out
+}
+
+func TestSource(t *testing.T) {
+	tests := []struct {
+		name   string
+		text   bool // Analisar com a sintaxe textual
+		source string
+		want   string
+	}{
+		{"espaços", false, "✍️   x=1+2\n🖨️x", "✍️ x = 1 + 2\n🖨️ x\n"},
+		{"comentário", false, "// soma\n✍️ x = 1 // um\n", "// soma\n✍️ x = 1 // um\n"},
+		{"linhas em branco", false, "✍️ x = 1\n\n\n\n🖨️ x\n", "✍️ x = 1\n\n🖨️ x\n"},
+		{"bloco", false, "▶️ f(a){↩️ a}", "▶️ f(a) {\n    ↩️ a\n}\n"},
+		{"operadores como escritos", false, "✍️ x = 2 * 3 ✖ 4\n🖨️ x == 1 🟰 true\n", "✍️ x = 2 * 3 ✖️ 4\n🖨️ x == 1 🟰 true\n"},
+		{"operadores em texto", true, "let x = 2 ✖️ (3*4)\nprint x==1\n", "let x = 2 ✖️ (3 * 4)\nprint x == 1\n"},
+		{"números como escritos", false, "✍️ x = 0xFF + 1_000\n", "✍️ x = 0xFF + 1_000\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keywords := lexer.DefaultKeywords()
+			if tt.text {
+				keywords = lexer.TextKeywords()
+			}
+			if got := formatString(t, tt.source, keywords); got != tt.want {
+				t.Errorf("Source(%q) =\n%s\nesperado\n%s", tt.source, got, tt.want)
+			}
+		})
+	}
+}
+
+// Formatar código já formatado não muda nada
+func TestSourceIdempotent(t *testing.T) {
+	sources := map[string]string{
+		"expressões":  "🖨️ 1+2 ✖️ (3 - -4) . \"a\" 🤝 🚫 true 🔀 x 🟰 1\n",
+		"atribuições": "✍️ x :🔢 = 1\nx += 2\nx++\n✍️ s = 📝(x)\n",
+		"funções":     "▶️ soma(a:🔢,b:🔢):🔢 { ↩️ a+b }\n// chamada\nsoma(1,2)\n",
+		"try/catch":   "🚀 bloco, 3 👨🏿‍💻 { 🖨️ ⌨️(\"nome: \") } 🤦🏿‍♂️ e {\n\n\n 🖨️ \"💱{e:>10}\" }\n",
+		"main":        "main ✍️ ✍️ { 🖨️ \"oi\" // dentro\n}\n",
+		"strings":     "🖨️ \"\"\"várias\nlinhas\"\"\"\n🖨️ \"a\\tb\"\n",
+	}
+	for _, path := range examples(t) {
+		code, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sources[filepath.Base(path)] = string(code)
+	}
+
+	for name, source := range sources {
+		t.Run(name, func(t *testing.T) {
+			keywords := lexer.DefaultKeywords()
+			once := formatString(t, source, keywords)
+			if twice := formatString(t, once, keywords); twice != once {
+				t.Errorf("formatar de novo mudou o código:\n%s\nvirou\n%s", once, twice)
+			}
+		})
+	}
+}
+
+// Nós que não cobrem todo o código fariam a formatação perder tokens
+func TestSourceRejectsUncoveredTokens(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		drop   func(nodes []ast.Node) []ast.Node
+	}{
+		{"instrução de fora", "🖨️ 1\n🖨️ 2", func(nodes []ast.Node) []ast.Node { return nodes[:1] }},
+		{"corpo de função", "▶️ f() { 🖨️ 1 }", func(nodes []ast.Node) []ast.Node {
+			nodes[0].(*ast.FunctionNode).Body = nil
+			return nodes
+		}},
+		{"bloco catch", "👨🏿‍💻 { 🖨️ 1 } 🤦🏿‍♂️ { 🖨️ 2 }", func(nodes []ast.Node) []ast.Node {
+			nodes[0].(*ast.TryCatchNode).CatchBody = nil
+			return nodes
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nodes := tt.drop(parser.NewParser(lexer.NewLexer(tt.source)).Parse())
+			if _, err := Source(tt.source, nodes); err == nil {
+				t.Errorf("Source(%q): esperado erro por token fora das instruções", tt.source)
+			}
+		})
+	}
+}
+
+// examples retorna os programas de exemplo do repositório
+func examples(t *testing.T) []string {
+	t.Helper()
+	paths, err := filepath.Glob(filepath.Join("..", "..", "examples", "*.mlz"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return paths
+}
@@ -0,0 +1,232 @@
+package format
+
+import (
+	"fmt"
+	"io"
+	"melhorzin-lang/internal/ast"
+	"melhorzin-lang/internal/convert"
+	"melhorzin-lang/internal/lexer"
+	"slices"
+	"strings"
+)
+
+// O parser descarta comentários, espaços e a grafia das palavras-chave, então
+// o formatador lê o código de novo com o lexer para recuperar o que a AST não
+// guarda: os comentários e linhas em branco (trivia), as anotações 🗑️
+// explícitas e a superfície (emoji ou texto) em que o programa foi escrito.
+
+// comment é um comentário // (ou a linha #! do início do arquivo)
+type comment struct {
+	pos, end int
+	text     string
+}
+
+// scan lê os tokens e os comentários do código. Os comentários ficam nos
+// intervalos entre tokens, fora de strings: uma string interpolada é tratada
+// como um bloco só, das aspas de abertura às de fechamento, pois as posições
+// dos tokens dentro de strings com três aspas são aproximadas.
+func scan(source string, keywords *lexer.Keywords) (tokens []lexer.Token, comments []comment, units []int) {
+	lex := lexer.NewLexerWithKeywords(source, keywords)
+	lex.SetErrorOutput(io.Discard)
+	tokens = lex.Lex()
+
+	prev := 0
+	for i := 0; i < len(tokens); i++ {
+		token := tokens[i]
+		if token.Pos > prev {
+			comments = appendComments(comments, source, prev, token.Pos)
+		}
+		if token.Type == lexer.TokenEOF {
+			prev = max(prev, token.Pos)
+			break
+		}
+		units = append(units, i)
+
+		end := token.End
+		if token.Type == lexer.TokenString {
+			// STRING (INTERPOLATE { expressão } STRING)*
+			for i+1 < len(tokens) && tokens[i+1].Type == lexer.TokenInterpolate {
+				i += 2 // 💱 {
+				for depth := 1; depth > 0 && i+1 < len(tokens); {
+					i++
+					switch tokens[i].Type {
+					case lexer.TokenLBrace:
+						depth++
+					case lexer.TokenRBrace:
+						depth--
+					}
+				}
+				if i+1 < len(tokens) {
+					i++ // Trecho da string depois da interpolação
+				}
+				end = tokens[i].End
+			}
+		}
+		prev = max(prev, end)
+	}
+	if prev < len(source) {
+		comments = appendComments(comments, source, prev, len(source))
+	}
+	return tokens, comments, units
+}
+
+// appendComments procura comentários em source[from:to], um trecho sem
+// strings
+func appendComments(comments []comment, source string, from, to int) []comment {
+	for i := from; i < to; i++ {
+		if !strings.HasPrefix(source[i:to], "//") && !(i == 0 && strings.HasPrefix(source, "#!")) {
+			continue
+		}
+		end := strings.IndexByte(source[i:to], '\n')
+		if end < 0 {
+			end = to - i
+		}
+		text := strings.TrimRight(source[i:i+end], " \t\r")
+		comments = append(comments, comment{pos: i, end: i + end, text: text})
+		i += end
+	}
+	return comments
+}
+
+// checkIgnored retorna um erro se algum token ficou fora das instruções do
+// programa, no nível superior ou dentro de blocos: nós que não cobrem todo o
+// código (só parte do programa, ou montados à mão) fariam a formatação
+// remover tokens sem aviso.
+// Parênteses ficam de fora da verificação, pois o trecho de uma expressão
+// entre parênteses não os inclui.
+func checkIgnored(tokens []lexer.Token, units []int, nodes []ast.Node) error {
+	next := 0
+	for _, i := range units {
+		token := tokens[i]
+		for next < len(nodes) && nodes[next].GetSpan().End <= token.Pos {
+			next++
+		}
+		if next < len(nodes) && nodes[next].GetSpan().Pos <= token.Pos {
+			continue
+		}
+		if token.Type == lexer.TokenLParen || token.Type == lexer.TokenRParen {
+			continue
+		}
+		return &lexer.Error{Pos: token.Pos, Message: fmt.Sprintf("Token inesperado %s (valor: %s)", token.Type, token.Value)}
+	}
+
+	// Os corpos dos blocos são verificados da mesma forma, com os tokens
+	// entre as chaves
+	for _, node := range nodes {
+		for _, b := range blocks(tokens, node) {
+			var inside []int
+			for _, i := range units {
+				if tokens[i].Pos >= b.from && tokens[i].Pos < b.to {
+					inside = append(inside, i)
+				}
+			}
+			if err := checkIgnored(tokens, inside, b.body); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// block é o corpo de um bloco e o trecho do código entre suas chaves
+type block struct {
+	body     []ast.Node
+	from, to int // Fim da { e início da }
+}
+
+// blocks retorna os blocos de uma instrução: o corpo de uma função ou de
+// main, ou os blocos try e catch
+func blocks(tokens []lexer.Token, node ast.Node) []block {
+	span := node.GetSpan()
+	switch n := node.(type) {
+	case *ast.FunctionNode:
+		return []block{{n.Body, tokens[findToken(tokens, lexer.TokenLBrace, span.Pos)].End, span.End - 1}}
+	case *ast.MainNode:
+		return []block{{n.Body, tokens[findToken(tokens, lexer.TokenLBrace, span.Pos)].End, span.End - 1}}
+	case *ast.TryCatchNode:
+		open := tokens[findToken(tokens, lexer.TokenLBrace, span.Pos)]
+		after := open.End
+		if len(n.TryBody) > 0 {
+			after = n.TryBody[len(n.TryBody)-1].GetSpan().End
+		}
+		catch := findToken(tokens, lexer.TokenCatch, after)
+		return []block{
+			{n.TryBody, open.End, tokens[catch-1].Pos},
+			{n.CatchBody, tokens[findToken(tokens, lexer.TokenLBrace, tokens[catch].End)].End, span.End - 1},
+		}
+	}
+	return nil
+}
+
+// findToken retorna o índice do primeiro token do tipo typ que começa em pos
+// ou depois, ou o do último token (EOF) se não houver nenhum
+func findToken(tokens []lexer.Token, typ lexer.TokenType, pos int) int {
+	i, _ := slices.BinarySearchFunc(tokens, pos, func(token lexer.Token, pos int) int {
+		return token.Pos - pos
+	})
+	for ; i < len(tokens); i++ {
+		if tokens[i].Type == typ {
+			return i
+		}
+	}
+	return len(tokens) - 1
+}
+
+// detectSurface escolhe a superfície usada pela maioria das palavras-chave
+// que têm as duas formas; na dúvida, emoji. Só contam as palavras (print,
+// let, ...): operadores como * e == também aparecem em programas em emoji.
+func detectSurface(tokens []lexer.Token) convert.Surface {
+	text, emoji := 0, 0
+	for _, token := range tokens {
+		word, ok := lexer.TextForm(token.Type)
+		if _, hasEmoji := lexer.EmojiForm(token.Type); !ok || !hasEmoji || !lexer.IsTextKeyword(word) {
+			continue
+		}
+		if token.Value == word {
+			text++
+		} else {
+			emoji++
+		}
+	}
+	if text > emoji {
+		return convert.Text
+	}
+	return convert.Emoji
+}
+
+// tokenAt retorna o índice do primeiro token que começa em pos ou depois
+func (p *printer) tokenAt(pos int) int {
+	i, _ := slices.BinarySearchFunc(p.tokens, pos, func(token lexer.Token, pos int) int {
+		return token.Pos - pos
+	})
+	return i
+}
+
+// find retorna o primeiro token do tipo typ que começa em pos ou depois
+func (p *printer) find(typ lexer.TokenType, pos int) lexer.Token {
+	return p.tokens[findToken(p.tokens, typ, pos)]
+}
+
+// comments escreve, cada um em sua linha, os comentários pendentes que
+// começam antes de pos
+func (p *printer) comments(pos int) {
+	for len(p.pending) > 0 && p.pending[0].pos < pos {
+		c := p.pending[0]
+		p.pending = p.pending[1:]
+		p.line(c.pos)
+		p.write(c.text)
+		p.newline(c.end)
+	}
+}
+
+// trailing escreve, no fim da linha atual, um comentário que no código estava
+// na mesma linha, depois de pos
+func (p *printer) trailing(pos int) {
+	if len(p.pending) == 0 || p.pending[0].pos < pos || strings.Contains(p.source[pos:p.pending[0].pos], "\n") {
+		return
+	}
+	c := p.pending[0]
+	p.pending = p.pending[1:]
+	p.write(" ", c.text)
+	p.last = c.end
+}
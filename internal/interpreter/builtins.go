@@ -0,0 +1,184 @@
+package interpreter
+
+import (
+	"io"
+	"math"
+	"math/rand/v2"
+	"melhorzin-lang/internal/ast"
+	"os"
+	"time"
+	"unicode/utf8"
+)
+
+// builtins são as funções nativas disponíveis em todo programa. As que
+// acessam o mundo fora do interpretador dependem das Capabilities do Runtime.
+var builtins = map[string]func(env *Env, args []interface{}) interface{}{
+	// lerArquivo(caminho) retorna o conteúdo de um arquivo
+	"lerArquivo": func(env *Env, args []interface{}) interface{} {
+		path := stringArg("lerArquivo", args, 0, 1)
+		root, rel, ok := resolvePath(env.Caps.ReadRoots, path)
+		require(ok, "ler "+path)
+
+		dir, err := os.OpenRoot(root)
+		if err != nil {
+			throw(ErrIO, "Erro ao ler %s: %v", path, err)
+		}
+		defer dir.Close()
+		file, err := dir.Open(rel)
+		if err != nil {
+			throw(ErrIO, "Erro ao ler %s: %v", path, err)
+		}
+		defer file.Close()
+
+		info, err := file.Stat()
+		if err != nil {
+			throw(ErrIO, "Erro ao ler %s: %v", path, err)
+		}
+		env.Alloc(int(info.Size()))
+		content, err := io.ReadAll(io.LimitReader(file, info.Size()))
+		if err != nil {
+			throw(ErrIO, "Erro ao ler %s: %v", path, err)
+		}
+		return string(content)
+	},
+
+	// escreverArquivo(caminho, texto) cria ou substitui um arquivo
+	"escreverArquivo": func(env *Env, args []interface{}) interface{} {
+		path := stringArg("escreverArquivo", args, 0, 2)
+		content := stringArg("escreverArquivo", args, 1, 2)
+		root, rel, ok := resolvePath(env.Caps.WriteRoots, path)
+		require(ok, "escrever "+path)
+
+		dir, err := os.OpenRoot(root)
+		if err != nil {
+			throw(ErrIO, "Erro ao escrever %s: %v", path, err)
+		}
+		defer dir.Close()
+		file, err := dir.OpenFile(rel, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o644)
+		if err != nil {
+			throw(ErrIO, "Erro ao escrever %s: %v", path, err)
+		}
+		_, err = io.WriteString(file, content)
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			throw(ErrIO, "Erro ao escrever %s: %v", path, err)
+		}
+		return nil
+	},
+
+	// ambiente(nome) retorna o valor de uma variável de ambiente, ou "" se
+	// ela não existir
+	"ambiente": func(env *Env, args []interface{}) interface{} {
+		name := stringArg("ambiente", args, 0, 1)
+		require(env.Caps.Env, "ler variáveis de ambiente")
+		return os.Getenv(name)
+	},
+
+	// agora() retorna o horário atual em segundos desde 1970 (Unix)
+	"agora": func(env *Env, args []interface{}) interface{} {
+		checkArgCount("agora", args, 0)
+		require(env.Caps.Time, "consultar o relógio")
+		return int(time.Now().Unix())
+	},
+
+	// aleatorio(min, max) retorna um número aleatório entre min e max,
+	// inclusive
+	"aleatorio": func(env *Env, args []interface{}) interface{} {
+		low := intArg("aleatorio", args, 0, 2)
+		high := intArg("aleatorio", args, 1, 2)
+		require(env.Caps.Random, "gerar números aleatórios")
+		if low > high {
+			throw(ErrArgument, "aleatorio: mínimo %d maior que máximo %d", low, high)
+		}
+		span := uint64(high) - uint64(low)
+		if span == math.MaxUint64 {
+			return int(rand.Uint64())
+		}
+		return low + int(rand.Uint64N(span+1))
+	},
+
+	// tamanho(valor) retorna o número de caracteres de uma string ou de
+	// itens de uma lista
+	"tamanho": func(env *Env, args []interface{}) interface{} {
+		checkArgCount("tamanho", args, 1)
+		switch v := args[0].(type) {
+		case string:
+			return utf8.RuneCountInString(v)
+		case []interface{}:
+			return len(v)
+		}
+		throw(ErrType, "Tipo incorreto para argumento 1 da função tamanho: esperado %s ou lista, recebido %s",
+			ast.TypeString, TypeOfValue(args[0]))
+		return nil
+	},
+
+	// item(lista, i) retorna o item na posição i de uma lista, contando a
+	// partir de 0
+	"item": func(env *Env, args []interface{}) interface{} {
+		checkArgCount("item", args, 2)
+		list, ok := args[0].([]interface{})
+		if !ok {
+			throw(ErrType, "Tipo incorreto para argumento 1 da função item: esperado lista, recebido %s",
+				TypeOfValue(args[0]))
+		}
+		i := intArg("item", args, 1, 2)
+		if i < 0 || i >= len(list) {
+			throw(ErrValue, "item: posição %d fora da lista de %d itens", i, len(list))
+		}
+		return list[i]
+	},
+
+	// sair(código) encerra o programa com o código de saída informado, ou 0
+	// se ele for omitido
+	"sair": func(env *Env, args []interface{}) interface{} {
+		require(env.Caps.Exit, "encerrar o programa")
+		code := 0
+		if len(args) > 0 {
+			code = intArg("sair", args, 0, 1)
+		}
+		if code < 0 || code > 255 {
+			throw(ErrArgument, "sair: código de saída %d fora do intervalo 0 a 255", code)
+		}
+		panic(&Exit{Code: code})
+	},
+}
+
+// RegisterBuiltins define as funções nativas da linguagem no ambiente.
+func RegisterBuiltins(env *Env) {
+	for name, fn := range builtins {
+		env.Vars[name] = &NativeFunction{Name: name, Fn: fn}
+	}
+}
+
+// checkArgCount verifica o número de argumentos de uma função nativa
+func checkArgCount(name string, args []interface{}, count int) {
+	if len(args) != count {
+		throw(ErrArgument, "Número incorreto de argumentos para função %s", name)
+	}
+}
+
+// stringArg retorna o argumento i de uma função nativa de count argumentos,
+// que deve ser uma string
+func stringArg(name string, args []interface{}, i, count int) string {
+	checkArgCount(name, args, count)
+	text, ok := args[i].(string)
+	if !ok {
+		throw(ErrType, "Tipo incorreto para argumento %d da função %s: esperado %s, recebido %s",
+			i+1, name, ast.TypeString, TypeOfValue(args[i]))
+	}
+	return text
+}
+
+// intArg retorna o argumento i de uma função nativa de count argumentos, que
+// deve ser um número
+func intArg(name string, args []interface{}, i, count int) int {
+	checkArgCount(name, args, count)
+	number, ok := args[i].(int)
+	if !ok {
+		throw(ErrType, "Tipo incorreto para argumento %d da função %s: esperado %s, recebido %s",
+			i+1, name, ast.TypeNumber, TypeOfValue(args[i]))
+	}
+	return number
+}
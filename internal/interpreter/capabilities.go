@@ -0,0 +1,69 @@
+package interpreter
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// Capabilities define o que um programa pode fazer fora do interpretador. O
+// valor zero é um sandbox puro: o programa só calcula, sem ler ou escrever
+// arquivos, consultar o ambiente, o relógio ou números aleatórios, imprimir
+// nem encerrar o processo.
+type Capabilities struct {
+	ReadRoots  []string // Diretórios cujos arquivos podem ser lidos
+	WriteRoots []string // Diretórios em que arquivos podem ser escritos
+	Env        bool     // Ler variáveis de ambiente
+	Time       bool     // Consultar o relógio
+	Random     bool     // Gerar números aleatórios
+	Stdout     bool     // Imprimir com 🖨️, mostrar prompts de ⌨️ e o eco de expressões
+	Exit       bool     // Encerrar o programa com sair(código)
+}
+
+// Sandbox retorna as capacidades de um sandbox puro, em que nada é permitido.
+func Sandbox() Capabilities {
+	return Capabilities{}
+}
+
+// Trusted retorna as capacidades de um programa confiável, em que tudo é
+// permitido.
+func Trusted() Capabilities {
+	all := []string{string(filepath.Separator)}
+	return Capabilities{
+		ReadRoots:  all,
+		WriteRoots: all,
+		Env:        true,
+		Time:       true,
+		Random:     true,
+		Stdout:     true,
+		Exit:       true,
+	}
+}
+
+// require lança um erro ErrPermission se a operação não for permitida
+func require(allowed bool, operation string) {
+	if !allowed {
+		throw(ErrPermission, "Permissão negada: %s não é permitido neste interpretador", operation)
+	}
+}
+
+// resolvePath encontra a raiz que contém path e retorna a raiz e o caminho
+// relativo a ela. O caminho relativo ainda deve ser aberto através de
+// os.Root, que impede escapar da raiz por links simbólicos.
+func resolvePath(roots []string, path string) (root, rel string, ok bool) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", "", false
+	}
+	for _, root := range roots {
+		rootAbs, err := filepath.Abs(root)
+		if err != nil {
+			continue
+		}
+		rel, err := filepath.Rel(rootAbs, abs)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			continue
+		}
+		return rootAbs, rel, true
+	}
+	return "", "", false
+}
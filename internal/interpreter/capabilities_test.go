@@ -0,0 +1,137 @@
+package interpreter
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestResolvePath(t *testing.T) {
+	base := t.TempDir()
+	data := filepath.Join(base, "dados")
+	other := filepath.Join(base, "dados2")
+
+	tests := []struct {
+		name  string
+		roots []string
+		path  string
+		root  string // Raiz esperada; vazia se o caminho deve ser negado
+		rel   string
+	}{
+		{"arquivo na raiz", []string{data}, filepath.Join(data, "a.txt"), data, "a.txt"},
+		{"subdiretório", []string{data}, filepath.Join(data, "x", "a.txt"), data, filepath.Join("x", "a.txt")},
+		{"a própria raiz", []string{data}, data, data, "."},
+		{"raiz com barra no fim", []string{data + string(filepath.Separator)}, filepath.Join(data, "a.txt"), data, "a.txt"},
+		{".. que volta para dentro", []string{data}, filepath.Join(data, "x", "..", "a.txt"), data, "a.txt"},
+		{".. que sai da raiz", []string{data}, filepath.Join(data, "..", "segredo.txt"), "", ""},
+		{"prefixo do nome não basta", []string{data}, filepath.Join(other, "a.txt"), "", ""},
+		{"arquivo cujo nome começa com ..", []string{data}, filepath.Join(data, "..a"), data, "..a"},
+		{"fora de todas as raízes", []string{data, other}, filepath.Join(base, "a.txt"), "", ""},
+		{"segunda raiz", []string{data, other}, filepath.Join(other, "a.txt"), other, "a.txt"},
+		{"sem raízes", nil, filepath.Join(data, "a.txt"), "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root, rel, ok := resolvePath(tt.roots, tt.path)
+			if tt.root == "" {
+				if ok {
+					t.Errorf("resolvePath(%q) = %q, %q; esperado negar", tt.path, root, rel)
+				}
+				return
+			}
+			if !ok || root != tt.root || rel != tt.rel {
+				t.Errorf("resolvePath(%q) = %q, %q, %v; esperado %q, %q", tt.path, root, rel, ok, tt.root, tt.rel)
+			}
+		})
+	}
+}
+
+func TestFileBuiltinsSandbox(t *testing.T) {
+	base := t.TempDir()
+	data := filepath.Join(base, "dados")
+	outside := filepath.Join(base, "fora")
+	for _, dir := range []string{data, outside} {
+		if err := os.Mkdir(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeFile(t, filepath.Join(data, "a.txt"), "olá")
+	writeFile(t, filepath.Join(outside, "segredo.txt"), "segredo")
+	// Um link dentro da raiz que aponta para fora dela
+	if err := os.Symlink(outside, filepath.Join(data, "atalho")); err != nil {
+		t.Skipf("links simbólicos não suportados: %v", err)
+	}
+
+	caps := Capabilities{ReadRoots: []string{data}, WriteRoots: []string{data}}
+	tests := []struct {
+		name string
+		fn   string
+		args []interface{}
+		want interface{}
+		kind ErrorKind // Tipo do erro esperado; vazio se não há erro
+	}{
+		{"ler dentro da raiz", "lerArquivo", []interface{}{filepath.Join(data, "a.txt")}, "olá", ""},
+		{"ler arquivo inexistente", "lerArquivo", []interface{}{filepath.Join(data, "b.txt")}, nil, ErrIO},
+		{"ler fora da raiz", "lerArquivo", []interface{}{filepath.Join(outside, "segredo.txt")}, nil, ErrPermission},
+		{"ler com ..", "lerArquivo", []interface{}{filepath.Join(data, "..", "fora", "segredo.txt")}, nil, ErrPermission},
+		{"ler por link simbólico", "lerArquivo", []interface{}{filepath.Join(data, "atalho", "segredo.txt")}, nil, ErrIO},
+		{"escrever dentro da raiz", "escreverArquivo", []interface{}{filepath.Join(data, "b.txt"), "oi"}, nil, ""},
+		{"escrever fora da raiz", "escreverArquivo", []interface{}{filepath.Join(outside, "b.txt"), "oi"}, nil, ErrPermission},
+		{"escrever por link simbólico", "escreverArquivo", []interface{}{filepath.Join(data, "atalho", "b.txt"), "oi"}, nil, ErrIO},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewInterpreter(caps).Call(context.Background(), tt.fn, tt.args)
+			if tt.kind != "" {
+				var runtimeErr *RuntimeError
+				if !errors.As(err, &runtimeErr) || runtimeErr.Kind != tt.kind {
+					t.Fatalf("%s = %v, %v; esperado erro do tipo %s", tt.fn, got, err, tt.kind)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("%s = %#v, %v; esperado %#v", tt.fn, got, err, tt.want)
+			}
+		})
+	}
+
+	// Nada foi escrito fora da raiz
+	if _, err := os.Stat(filepath.Join(outside, "b.txt")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("arquivo escrito fora da raiz: %v", err)
+	}
+}
+
+func TestSandboxDeniesEverything(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "a.txt")
+	writeFile(t, path, "olá")
+
+	calls := []struct {
+		fn   string
+		args []interface{}
+	}{
+		{"lerArquivo", []interface{}{path}},
+		{"escreverArquivo", []interface{}{path, "oi"}},
+		{"ambiente", []interface{}{"HOME"}},
+		{"agora", nil},
+		{"aleatorio", []interface{}{1, 6}},
+		{"sair", []interface{}{1}},
+	}
+	for _, call := range calls {
+		t.Run(call.fn, func(t *testing.T) {
+			_, err := NewInterpreter(Sandbox()).Call(context.Background(), call.fn, call.args)
+			var runtimeErr *RuntimeError
+			if !errors.As(err, &runtimeErr) || runtimeErr.Kind != ErrPermission {
+				t.Errorf("%s no sandbox = %v, esperado erro de permissão", call.fn, err)
+			}
+		})
+	}
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
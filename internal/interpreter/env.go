@@ -0,0 +1,168 @@
+package interpreter
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"maps"
+	"os"
+	"strings"
+)
+
+// Env é o ambiente de execução passado a todos os nós: as variáveis visíveis
+// e o estado compartilhado por toda a execução.
+type Env struct {
+	Vars map[string]interface{}
+	*Runtime
+}
+
+// Runtime é o estado de uma execução compartilhado entre o ambiente global e
+// os ambientes locais das funções.
+type Runtime struct {
+	Stdout io.Writer     // Saída de 🖨️
+	Stderr io.Writer     // Saída de diagnósticos
+	Stdin  *bufio.Reader // Entrada lida pelo programa
+	Caps   Capabilities  // O que o programa pode fazer fora do interpretador
+
+	// MaxSteps limita quantos nós podem ser avaliados em uma execução; 0
+	// significa sem limite
+	MaxSteps int
+
+	// MaxAlloc limita quantos bytes uma execução pode alocar em strings,
+	// somando todas as alocações; 0 significa sem limite
+	MaxAlloc int
+
+	// MaxDepth limita quantas chamadas de função podem estar ativas ao mesmo
+	// tempo; 0 significa DefaultMaxDepth
+	MaxDepth int
+
+	ctx       context.Context
+	steps     int
+	allocated int
+	depth     int
+}
+
+// DefaultMaxDepth é o limite padrão de chamadas aninhadas. Cada chamada usa a
+// pilha do Go e uma cópia das variáveis, então uma recursão sem fim precisa
+// parar com um erro antes de derrubar o processo.
+const DefaultMaxDepth = 10000
+
+// ctxCheckInterval é de quantos em quantos passos o contexto é consultado
+const ctxCheckInterval = 256
+
+// NewRuntime cria o estado de execução com a entrada e as saídas informadas.
+// Saídas nil descartam o que é escrito e uma entrada nil está sempre no fim.
+func NewRuntime(stdin io.Reader, stdout, stderr io.Writer) *Runtime {
+	r := &Runtime{ctx: context.Background()}
+	r.SetIO(stdin, stdout, stderr)
+	return r
+}
+
+// NewStdRuntime cria o estado de execução ligado à entrada e às saídas padrão
+// do processo.
+func NewStdRuntime() *Runtime {
+	return NewRuntime(os.Stdin, os.Stdout, os.Stderr)
+}
+
+// SetIO troca a entrada e as saídas. Saídas nil descartam o que é escrito e
+// uma entrada nil está sempre no fim.
+func (r *Runtime) SetIO(stdin io.Reader, stdout, stderr io.Writer) {
+	if stdin == nil {
+		stdin = strings.NewReader("")
+	}
+	if stdout == nil {
+		stdout = io.Discard
+	}
+	if stderr == nil {
+		stderr = io.Discard
+	}
+	r.Stdin, r.Stdout, r.Stderr = bufio.NewReader(stdin), stdout, stderr
+}
+
+// Start inicia uma execução: zera a contagem de passos e passa a observar o
+// cancelamento e o prazo de ctx.
+func (r *Runtime) Start(ctx context.Context) {
+	r.ctx = ctx
+	r.steps = 0
+	r.allocated = 0
+	r.depth = 0
+	r.checkContext()
+}
+
+// Step conta a avaliação de um nó e interrompe a execução se o limite de
+// passos for excedido ou o contexto for cancelado.
+func (r *Runtime) Step() {
+	r.steps++
+	if r.MaxSteps > 0 && r.steps > r.MaxSteps {
+		panic(&Interrupt{Err: ErrStepLimit})
+	}
+	if r.steps%ctxCheckInterval == 0 {
+		r.checkContext()
+	}
+}
+
+// Alloc conta bytes alocados pelo programa e lança um erro ErrResource se o
+// limite de memória for excedido. Deve ser chamado antes de alocar, para que
+// o limite proteja o host de fato. Uma alocação recusada não é contada, então
+// um programa que captura o erro ainda pode fazer alocações menores. Um
+// tamanho negativo, que só surge de uma conta que estourou, também é recusado.
+func (r *Runtime) Alloc(bytes int) {
+	if bytes < 0 {
+		throw(ErrResource, "Limite de memória excedido: tamanho de alocação inválido (%d bytes)", bytes)
+	}
+	if r.MaxAlloc > 0 && bytes > r.MaxAlloc-r.allocated {
+		throw(ErrResource, "Limite de memória excedido: a execução tentou alocar mais de %d bytes", r.MaxAlloc)
+	}
+	r.allocated += bytes
+}
+
+// Enter conta o início de uma chamada de função e lança um erro ErrResource
+// se o limite de chamadas aninhadas for excedido. Cada Enter deve ser seguido
+// de um Leave quando a chamada terminar.
+func (r *Runtime) Enter() {
+	limit := r.MaxDepth
+	if limit <= 0 {
+		limit = DefaultMaxDepth
+	}
+	if r.depth >= limit {
+		throw(ErrResource, "Limite de recursão excedido: mais de %d chamadas aninhadas", limit)
+	}
+	r.depth++
+}
+
+// Leave conta o fim de uma chamada de função iniciada com Enter.
+func (r *Runtime) Leave() {
+	r.depth--
+}
+
+// ReadLine lê uma linha de Stdin, incluindo a quebra de linha. A linha é
+// lida em pedaços do tamanho do buffer, contados no limite de memória antes de
+// serem acumulados.
+func (r *Runtime) ReadLine() (string, error) {
+	var line []byte
+	for {
+		chunk, err := r.Stdin.ReadSlice('\n')
+		r.Alloc(len(chunk))
+		line = append(line, chunk...)
+		if err != bufio.ErrBufferFull {
+			return string(line), err
+		}
+	}
+}
+
+func (r *Runtime) checkContext() {
+	if err := r.ctx.Err(); err != nil {
+		panic(&Interrupt{Err: err})
+	}
+}
+
+// NewEnv cria um ambiente global vazio.
+func NewEnv(runtime *Runtime) *Env {
+	return &Env{Vars: make(map[string]interface{}), Runtime: runtime}
+}
+
+// Local cria o ambiente de uma chamada de função: uma cópia das variáveis
+// visíveis, compartilhando o estado de execução.
+func (e *Env) Local() *Env {
+	return &Env{Vars: maps.Clone(e.Vars), Runtime: e.Runtime}
+}
@@ -0,0 +1,89 @@
+package interpreter
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+// catch executa fn e retorna o erro de execução que ela lançar
+func catch(fn func()) (err *RuntimeError) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = r.(*RuntimeError)
+		}
+	}()
+	fn()
+	return nil
+}
+
+func TestAlloc(t *testing.T) {
+	tests := []struct {
+		name   string
+		max    int
+		allocs []int
+		fail   bool // A última alocação deve ser recusada
+	}{
+		{"sem limite", 0, []int{1 << 40, 1 << 40}, false},
+		{"dentro do limite", 100, []int{60, 40}, false},
+		{"acima do limite", 100, []int{60, 41}, true},
+		{"uma alocação enorme", 100, []int{math.MaxInt}, true},
+		{"tamanho negativo", 100, []int{10, -1}, true},
+		{"negativo sem limite", 0, []int{math.MinInt}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewRuntime(nil, nil, nil)
+			r.MaxAlloc = tt.max
+			for i, size := range tt.allocs {
+				err := catch(func() { r.Alloc(size) })
+				last := i == len(tt.allocs)-1
+				if err != nil && (!last || !tt.fail) {
+					t.Fatalf("Alloc(%d): erro inesperado %v", size, err)
+				}
+				if last && tt.fail && (err == nil || err.Kind != ErrResource) {
+					t.Fatalf("Alloc(%d) = %v, esperado erro do tipo %s", size, err, ErrResource)
+				}
+			}
+		})
+	}
+
+	// Uma alocação recusada não é contada
+	r := NewRuntime(nil, nil, nil)
+	r.MaxAlloc = 100
+	catch(func() { r.Alloc(-50) })
+	catch(func() { r.Alloc(101) })
+	if err := catch(func() { r.Alloc(100) }); err != nil {
+		t.Errorf("Alloc(100) depois de alocações recusadas: %v", err)
+	}
+}
+
+func TestAllocLimitInPrograms(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+	}{
+		{"concatenação", `✍️ s = "aaaaaaaaaa"
+s .= s
+s .= s
+s .= s
+s .= s
+s .= s
+s .= s
+s .= s`},
+		{"largura", `🖨️ "💱{1:>5000}"`},
+		{"preenchimento de 4 bytes", `🖨️ "💱{1:😀>300}"`},
+		{"precisão", `🖨️ "💱{1:.5000}"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			interp := NewInterpreter(Capabilities{Stdout: true})
+			interp.SetMaxAlloc(1000)
+			_, err := interpret(t, interp, tt.source)
+			var runtimeErr *RuntimeError
+			if !errors.As(err, &runtimeErr) || runtimeErr.Kind != ErrResource {
+				t.Errorf("erro %v, esperado erro do tipo %s", err, ErrResource)
+			}
+		})
+	}
+}
@@ -0,0 +1,145 @@
+package interpreter
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+)
+
+// ErrorKind classifica um erro de execução.
+type ErrorKind string
+
+const (
+	ErrType       ErrorKind = "TYPE"
+	ErrName       ErrorKind = "NAME"
+	ErrArgument   ErrorKind = "ARGUMENT"
+	ErrFormat     ErrorKind = "FORMAT"
+	ErrOverflow   ErrorKind = "OVERFLOW"
+	ErrValue      ErrorKind = "VALUE"      // Texto que não pode ser convertido
+	ErrInput      ErrorKind = "INPUT"      // Fim da entrada ou erro de leitura
+	ErrResource   ErrorKind = "RESOURCE"   // Limite de memória ou de recursão excedido
+	ErrPermission ErrorKind = "PERMISSION" // Operação não permitida pelas Capabilities
+	ErrIO         ErrorKind = "IO"         // Erro ao acessar arquivos
+	ErrHost       ErrorKind = "HOST"       // Erro retornado por uma função do host
+)
+
+// RuntimeError é um erro de execução do programa, que pode ser capturado por
+// um bloco 👨🏿‍💻 { ... } 🤦🏿‍♂️ { ... }.
+type RuntimeError struct {
+	Kind    ErrorKind
+	Message string
+}
+
+func (e *RuntimeError) Error() string {
+	return e.Message
+}
+
+// ErrStepLimit indica que a execução excedeu o número máximo de passos.
+var ErrStepLimit = errors.New("limite de passos excedido")
+
+// Interrupt interrompe a execução por um motivo externo ao programa:
+// cancelamento ou prazo do contexto (Err é ctx.Err()) ou limite de passos (Err
+// é ErrStepLimit). Ao contrário de RuntimeError, não pode ser capturado por
+// 👨🏿‍💻/🤦🏿‍♂️.
+type Interrupt struct {
+	Err error
+}
+
+func (e *Interrupt) Error() string {
+	return "Execução interrompida: " + e.Err.Error()
+}
+
+func (e *Interrupt) Unwrap() error {
+	return e.Err
+}
+
+// Exit encerra o programa a pedido dele mesmo, pela função nativa sair. Assim
+// como Interrupt, não pode ser capturado por 👨🏿‍💻/🤦🏿‍♂️; cabe ao host decidir o
+// que fazer com o código de saída.
+type Exit struct {
+	Code int
+}
+
+func (e *Exit) Error() string {
+	return fmt.Sprintf("Programa encerrado com código %d", e.Code)
+}
+
+// throw interrompe a avaliação com um erro de execução capturável
+func throw(kind ErrorKind, format string, args ...interface{}) {
+	panic(&RuntimeError{Kind: kind, Message: fmt.Sprintf(format, args...)})
+}
+
+// catchRuntimeError executa fn e retorna o erro de execução que ela lançar,
+// ou nil. Outros panics são propagados.
+func catchRuntimeError(fn func()) (err *RuntimeError) {
+	defer func() {
+		if r := recover(); r != nil {
+			runtimeErr, ok := r.(*RuntimeError)
+			if !ok {
+				panic(r)
+			}
+			err = runtimeErr
+		}
+	}()
+	fn()
+	return nil
+}
+
+// Aritmética inteira com detecção de estouro: em vez de dar a volta
+// silenciosamente, a operação lança um erro ErrOverflow.
+
+func addInt(a, b int) int {
+	result := a + b
+	if (a > 0 && b > 0 && result < 0) || (a < 0 && b < 0 && result >= 0) {
+		throw(ErrOverflow, "Estouro de inteiro: %d + %d", a, b)
+	}
+	return result
+}
+
+func subInt(a, b int) int {
+	result := a - b
+	if (a >= 0 && b < 0 && result < 0) || (a < 0 && b > 0 && result >= 0) {
+		throw(ErrOverflow, "Estouro de inteiro: %d - %d", a, b)
+	}
+	return result
+}
+
+func mulInt(a, b int) int {
+	if a == 0 || b == 0 {
+		return 0
+	}
+	result := a * b
+	if result/b != a || (a == -1 && b == math.MinInt) || (b == -1 && a == math.MinInt) {
+		throw(ErrOverflow, "Estouro de inteiro: %d * %d", a, b)
+	}
+	return result
+}
+
+func negInt(a int) int {
+	if a == math.MinInt {
+		throw(ErrOverflow, "Estouro de inteiro: -(%d)", a)
+	}
+	return -a
+}
+
+// Deslocamentos de bits. O número de posições deve estar entre 0 e 63, e um
+// deslocamento à esquerda que perca bits significativos é um estouro.
+
+func shiftLeft(a, n int) int {
+	if n < 0 || n >= strconv.IntSize {
+		throw(ErrArgument, "Deslocamento inválido: %d << %d", a, n)
+	}
+	result := a << n
+	if result>>n != a {
+		throw(ErrOverflow, "Estouro de inteiro: %d << %d", a, n)
+	}
+	return result
+}
+
+func shiftRight(a, n int) int {
+	if n < 0 || n >= strconv.IntSize {
+		throw(ErrArgument, "Deslocamento inválido: %d >> %d", a, n)
+	}
+	return a >> n
+}
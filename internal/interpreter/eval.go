@@ -0,0 +1,433 @@
+package interpreter
+
+import (
+	"fmt"
+	"io"
+	"melhorzin-lang/internal/ast"
+	"melhorzin-lang/internal/lexer"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// eval avalia um nó da AST no ambiente env e retorna o seu valor. Cada
+// avaliação conta um passo da execução.
+func eval(env *Env, node ast.Node) interface{} {
+	env.Step()
+	switch n := node.(type) {
+	case *ast.PrintNode:
+		return evalPrint(env, n)
+	case *ast.AssignNode:
+		return evalAssign(env, n)
+	case *ast.EqualNode:
+		return valuesEqual(eval(env, n.Left), eval(env, n.Right))
+	case *ast.BinaryOpNode:
+		return evalBinaryOp(env, n)
+	case *ast.LogicalNode:
+		return evalLogical(env, n)
+	case *ast.UnaryOpNode:
+		return evalUnaryOp(env, n)
+	case *ast.VariableNode:
+		if val, exists := env.Vars[n.Name]; exists {
+			return val
+		}
+		return nil
+	case *ast.CompoundAssignNode:
+		if _, exists := env.Vars[n.Name]; !exists {
+			throw(ErrName, "Variável %s não definida", n.Name)
+		}
+		env.Vars[n.Name] = eval(env, n.BinaryOp())
+		return nil
+	case *ast.FunctionNode:
+		// Armazena a função no mapa de variáveis
+		env.Vars[n.Name] = n
+		return nil
+	case *ast.ReturnNode:
+		return eval(env, n.Value)
+	case *ast.FunctionCallNode:
+		args := make([]interface{}, len(n.Arguments))
+		for i, argNode := range n.Arguments {
+			args[i] = eval(env, argNode)
+		}
+		return CallFunction(env, n.Name, args)
+	case *ast.MainNode:
+		for _, node := range n.Body {
+			eval(env, node)
+		}
+		return nil
+	case *ast.TryCatchNode:
+		return evalTryCatch(env, n)
+	case *ast.NumberLiteralNode:
+		return n.Value
+	case *ast.StringLiteralNode:
+		return n.Value
+	case *ast.BooleanLiteralNode:
+		return n.Value
+	case *ast.InterpolatedStringNode:
+		var sb strings.Builder
+		for _, part := range n.Parts {
+			sb.WriteString(Stringify(eval(env, part)))
+		}
+		env.Alloc(sb.Len())
+		return sb.String()
+	case *ast.InterpolationNode:
+		return formatValue(env, eval(env, n.Expr), n.Format)
+	case *ast.InputNode:
+		return evalInput(env, n)
+	case *ast.ConvertNode:
+		return evalConvert(env, n)
+	}
+	panic(fmt.Sprintf("eval: tipo de nó inesperado %T", node))
+}
+
+func evalPrint(env *Env, n *ast.PrintNode) interface{} {
+	require(env.Caps.Stdout, "imprimir")
+	texts := make([]string, len(n.Values))
+	for i, value := range n.Values {
+		texts[i] = Stringify(eval(env, value))
+	}
+	text := strings.Join(texts, " ")
+	env.Alloc(len(text))
+	fmt.Fprintln(env.Stdout, text)
+	return text
+}
+
+// Stringify converte um valor da linguagem para o texto usado ao imprimir,
+// concatenar e interpolar.
+func Stringify(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "nulo"
+	case string:
+		return v
+	case int:
+		return strconv.Itoa(v)
+	case bool:
+		if v {
+			return "true"
+		}
+		return "false"
+	case *ast.FunctionNode:
+		return fmt.Sprintf("<função %s>", v.Name)
+	case *NativeFunction:
+		return fmt.Sprintf("<função nativa %s>", v.Name)
+	case []interface{}:
+		items := make([]string, len(v))
+		for i, item := range v {
+			items[i] = Stringify(item)
+		}
+		return "[" + strings.Join(items, ", ") + "]"
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
+
+func evalAssign(env *Env, n *ast.AssignNode) interface{} {
+	value := eval(env, n.Value)
+
+	// Verificação de tipo dinâmica; se o tipo estático é desconhecido,
+	// usa o tipo do valor calculado
+	valueType := n.Value.GetType()
+	if valueType == ast.TypeAny {
+		valueType = TypeOfValue(value)
+	}
+	if n.DeclaredType != ast.TypeAny && n.DeclaredType != valueType {
+		throw(ErrType, "Erro de tipo: esperado %s para variável %s, mas recebeu %s",
+			n.DeclaredType, n.Name, valueType)
+	}
+
+	env.Vars[n.Name] = value
+	return nil
+}
+
+// TypeOfValue retorna o tipo da linguagem correspondente a um valor em tempo
+// de execução
+func TypeOfValue(value interface{}) ast.Type {
+	switch value.(type) {
+	case int:
+		return ast.TypeNumber
+	case string:
+		return ast.TypeString
+	case bool:
+		return ast.TypeBool
+	default:
+		return ast.TypeAny
+	}
+}
+
+// valuesEqual compara dois valores da linguagem; valores de tipos diferentes
+// nunca são iguais. Listas são iguais se têm os mesmos itens, na mesma ordem.
+func valuesEqual(left, right interface{}) bool {
+	leftList, leftIsList := left.([]interface{})
+	rightList, rightIsList := right.([]interface{})
+	if leftIsList || rightIsList {
+		if !leftIsList || !rightIsList || len(leftList) != len(rightList) {
+			return false
+		}
+		for i := range leftList {
+			if !valuesEqual(leftList[i], rightList[i]) {
+				return false
+			}
+		}
+		return true
+	}
+
+	// Valores do host que Go não sabe comparar (mapas, funções, ...)
+	for _, value := range []interface{}{left, right} {
+		if value != nil && !reflect.TypeOf(value).Comparable() {
+			throw(ErrType, "Erro de tipo: valores do tipo %T não podem ser comparados", value)
+		}
+	}
+	return left == right
+}
+
+func evalBinaryOp(env *Env, n *ast.BinaryOpNode) interface{} {
+	leftVal := eval(env, n.Left)
+	rightVal := eval(env, n.Right)
+
+	switch n.Op {
+	case lexer.TokenPlus:
+		// + agora é só para soma numérica
+		leftInt, rightInt := numericOperands(n, "+", leftVal, rightVal)
+		return addInt(leftInt, rightInt)
+	case lexer.TokenMinus:
+		leftInt, rightInt := numericOperands(n, "-", leftVal, rightVal)
+		return subInt(leftInt, rightInt)
+	case lexer.TokenConcat:
+		// . é para concatenação de strings
+		left, right := Stringify(leftVal), Stringify(rightVal)
+		env.Alloc(len(left) + len(right))
+		return left + right
+	case lexer.TokenNumPlus:
+		// ➕ é para soma numérica (manter por compatibilidade)
+		leftInt, rightInt := numericOperands(n, "➕", leftVal, rightVal)
+		return addInt(leftInt, rightInt)
+	case lexer.TokenMult:
+		// ✖️ é para multiplicação numérica
+		leftInt, rightInt := numericOperands(n, "*", leftVal, rightVal)
+		return mulInt(leftInt, rightInt)
+	case lexer.TokenBitAnd:
+		leftInt, rightInt := numericOperands(n, "&", leftVal, rightVal)
+		return leftInt & rightInt
+	case lexer.TokenBitOr:
+		leftInt, rightInt := numericOperands(n, "|", leftVal, rightVal)
+		return leftInt | rightInt
+	case lexer.TokenBitXor:
+		leftInt, rightInt := numericOperands(n, "^", leftVal, rightVal)
+		return leftInt ^ rightInt
+	case lexer.TokenShiftLeft:
+		leftInt, rightInt := numericOperands(n, "<<", leftVal, rightVal)
+		return shiftLeft(leftInt, rightInt)
+	case lexer.TokenShiftRight:
+		leftInt, rightInt := numericOperands(n, ">>", leftVal, rightVal)
+		return shiftRight(leftInt, rightInt)
+	}
+	return nil
+}
+
+// numericOperands verifica se os dois operandos são números, tanto pelo tipo
+// estático (quando conhecido) quanto pelo valor em tempo de execução.
+func numericOperands(n *ast.BinaryOpNode, symbol string, leftVal, rightVal interface{}) (int, int) {
+	return numericOperand(symbol, n.Left, leftVal), numericOperand(symbol, n.Right, rightVal)
+}
+
+func evalLogical(env *Env, n *ast.LogicalNode) interface{} {
+	symbol := "and"
+	if n.Op == lexer.TokenOr {
+		symbol = "or"
+	}
+
+	left := boolOperand(symbol, n.Left, eval(env, n.Left))
+	// Curto-circuito: o lado direito só é avaliado se puder mudar o resultado
+	if n.Op == lexer.TokenAnd && !left {
+		return false
+	}
+	if n.Op == lexer.TokenOr && left {
+		return true
+	}
+	return boolOperand(symbol, n.Right, eval(env, n.Right))
+}
+
+func evalUnaryOp(env *Env, n *ast.UnaryOpNode) interface{} {
+	value := eval(env, n.Operand)
+
+	switch n.Op {
+	case lexer.TokenNot:
+		return !boolOperand("not", n.Operand, value)
+	case lexer.TokenMinus:
+		return negInt(numericOperand("-", n.Operand, value))
+	case lexer.TokenPlus, lexer.TokenNumPlus:
+		return numericOperand("+", n.Operand, value)
+	}
+	return nil
+}
+
+// numericOperand verifica se o operando de uma operação é um número, tanto
+// pelo tipo estático (quando conhecido) quanto pelo valor em tempo de
+// execução.
+func numericOperand(symbol string, node ast.Node, value interface{}) int {
+	if t := node.GetType(); t != ast.TypeNumber && t != ast.TypeAny {
+		throw(ErrType, "Erro de tipo: Operação %s requer operandos do tipo NUMBER", symbol)
+	}
+
+	number, ok := value.(int)
+	if !ok {
+		throw(ErrType, "Erro de tipo: Operação %s requer operandos do tipo NUMBER", symbol)
+	}
+	return number
+}
+
+// boolOperand verifica se o operando de uma operação lógica é booleano, tanto
+// pelo tipo estático (quando conhecido) quanto pelo valor em tempo de execução.
+func boolOperand(symbol string, node ast.Node, value interface{}) bool {
+	if t := node.GetType(); t != ast.TypeBool && t != ast.TypeAny {
+		throw(ErrType, "Erro de tipo: Operação %s requer operandos do tipo BOOL", symbol)
+	}
+
+	b, ok := value.(bool)
+	if !ok {
+		throw(ErrType, "Erro de tipo: Operação %s requer operandos do tipo BOOL", symbol)
+	}
+	return b
+}
+
+// NativeFunction é uma função implementada em Go e registrada pelo host. Os
+// argumentos chegam como valores da linguagem (int, string, bool, nil); erros
+// devem ser lançados como *RuntimeError.
+type NativeFunction struct {
+	Name string
+	Fn   func(env *Env, args []interface{}) interface{}
+}
+
+// CallFunction chama a função name, definida na linguagem ou registrada pelo
+// host, com argumentos já avaliados.
+func CallFunction(env *Env, name string, args []interface{}) interface{} {
+	switch fn := env.Vars[name].(type) {
+	case *ast.FunctionNode:
+		return callFunction(env, fn, args)
+	case *NativeFunction:
+		return fn.Fn(env, args)
+	case nil:
+		if _, exists := env.Vars[name]; !exists {
+			throw(ErrName, "Função %s não definida", name)
+		}
+	}
+	throw(ErrType, "Erro de tipo: %s não é uma função", name)
+	return nil
+}
+
+// callFunction executa o corpo da função em um ambiente local com os
+// parâmetros associados aos argumentos
+func callFunction(env *Env, fn *ast.FunctionNode, args []interface{}) interface{} {
+	if len(args) != len(fn.Parameters) {
+		throw(ErrArgument, "Número incorreto de argumentos para função %s", fn.Name)
+	}
+
+	env.Enter()
+	defer env.Leave()
+
+	// Criar ambiente local para a função
+	local := env.Local()
+
+	for i, argValue := range args {
+		// Verificar se o tipo do argumento é compatível com o tipo do parâmetro
+		argType := TypeOfValue(argValue)
+		if fn.ParamTypes[i] != ast.TypeAny && fn.ParamTypes[i] != argType {
+			throw(ErrType, "Tipo incorreto para argumento %d da função %s: esperado %s, recebido %s",
+				i+1, fn.Name, fn.ParamTypes[i], argType)
+		}
+		local.Vars[fn.Parameters[i]] = argValue
+	}
+
+	// Executar o corpo da função
+	var result interface{}
+	for _, node := range fn.Body {
+		if returnNode, ok := node.(*ast.ReturnNode); ok {
+			// Se encontrar um return, avalia e retorna
+			returnValue := eval(local, returnNode.Value)
+			returnType := returnNode.GetType()
+			if returnType == ast.TypeAny {
+				returnType = TypeOfValue(returnValue)
+			}
+
+			// Verificar se o tipo de retorno é compatível
+			if fn.ReturnType != ast.TypeAny && fn.ReturnType != returnType {
+				throw(ErrType, "Tipo de retorno incorreto para função %s: esperado %s, recebido %s",
+					fn.Name, fn.ReturnType, returnType)
+			}
+
+			return returnValue
+		}
+		// Avaliar cada nó no corpo da função; um PrintNode já imprimiu seu
+		// conteúdo
+		result = eval(local, node)
+	}
+	return result
+}
+
+func evalTryCatch(env *Env, n *ast.TryCatchNode) interface{} {
+	var err *RuntimeError
+	for attempt := 0; attempt < max(n.Attempts, 1); attempt++ {
+		err = catchRuntimeError(func() {
+			for _, node := range n.TryBody {
+				eval(env, node)
+			}
+		})
+		if err == nil {
+			return nil
+		}
+	}
+
+	env.Vars[n.ErrorVar] = err.Message
+	for _, node := range n.CatchBody {
+		eval(env, node)
+	}
+	return nil
+}
+
+func evalInput(env *Env, n *ast.InputNode) interface{} {
+	if n.Prompt != nil {
+		require(env.Caps.Stdout, "imprimir")
+		fmt.Fprint(env.Stdout, Stringify(eval(env, n.Prompt)))
+	}
+
+	line, err := env.ReadLine()
+	if err != nil && (err != io.EOF || line == "") {
+		if err == io.EOF {
+			throw(ErrInput, "Fim da entrada")
+		}
+		throw(ErrInput, "Erro ao ler a entrada: %v", err)
+	}
+	return strings.TrimRight(line, "\r\n")
+}
+
+// evalConvert converte o valor para o tipo pedido; textos que não
+// representam um valor desse tipo lançam um erro ErrValue
+func evalConvert(env *Env, n *ast.ConvertNode) interface{} {
+	value := eval(env, n.Value)
+	if TypeOfValue(value) == n.To {
+		return value
+	}
+
+	switch n.To {
+	case ast.TypeString:
+		text := Stringify(value)
+		env.Alloc(len(text))
+		return text
+	case ast.TypeNumber:
+		if text, ok := value.(string); ok {
+			if number, err := lexer.ParseNumber(strings.TrimSpace(text)); err == nil {
+				return number
+			}
+		}
+	case ast.TypeBool:
+		switch text, _ := value.(string); strings.TrimSpace(text) {
+		case "true":
+			return true
+		case "false":
+			return false
+		}
+	}
+	throw(ErrValue, "Não é possível converter %q para %s", Stringify(value), n.To)
+	return nil
+}
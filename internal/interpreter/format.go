@@ -0,0 +1,184 @@
+package interpreter
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// formatSpec representa uma especificação de formato de interpolação, no
+// estilo [[preenchimento]alinhamento][0][largura][.precisão][tipo].
+//
+// Exemplos: 💱{preco:.2f}, 💱{codigo:08x}, 💱{nome:>10}, 💱{titulo:*^20}
+type formatSpec struct {
+	fill      rune
+	align     rune // '<', '>', '^' ou 0 para o padrão do tipo
+	zero      bool
+	width     int
+	precision int // -1 quando não informada
+	verb      byte
+}
+
+// maxFormatWidth é a maior largura ou precisão aceita em uma especificação de
+// formato
+const maxFormatWidth = 1_000_000
+
+// parseFormatSpec interpreta o texto depois de : em uma interpolação
+func parseFormatSpec(spec string) formatSpec {
+	f := formatSpec{fill: ' ', precision: -1}
+	rest := spec
+
+	// Preenchimento e alinhamento
+	if first, size := utf8.DecodeRuneInString(rest); size > 0 && len(rest) > size && strings.ContainsRune("<>^", rune(rest[size])) {
+		f.fill = first
+		f.align = rune(rest[size])
+		rest = rest[size+1:]
+	} else if len(rest) > 0 && strings.ContainsRune("<>^", rune(rest[0])) {
+		f.align = rune(rest[0])
+		rest = rest[1:]
+	}
+
+	if strings.HasPrefix(rest, "0") {
+		f.zero = true
+		rest = rest[1:]
+	}
+
+	digits := 0
+	for digits < len(rest) && rest[digits] >= '0' && rest[digits] <= '9' {
+		digits++
+	}
+	if digits > 0 {
+		f.width = formatSize(spec, "largura", rest[:digits])
+		rest = rest[digits:]
+	}
+
+	if strings.HasPrefix(rest, ".") {
+		rest = rest[1:]
+		digits = 0
+		for digits < len(rest) && rest[digits] >= '0' && rest[digits] <= '9' {
+			digits++
+		}
+		if digits == 0 {
+			throw(ErrFormat, "Especificação de formato inválida: %s", spec)
+		}
+		f.precision = formatSize(spec, "precisão", rest[:digits])
+		rest = rest[digits:]
+	}
+
+	switch len(rest) {
+	case 0:
+	case 1:
+		if !strings.Contains("dxXobfes", rest) {
+			throw(ErrFormat, "Especificação de formato inválida: %s", spec)
+		}
+		f.verb = rest[0]
+	default:
+		throw(ErrFormat, "Especificação de formato inválida: %s", spec)
+	}
+	return f
+}
+
+// formatSize converte a largura ou a precisão de uma especificação de formato,
+// lançando um erro ErrFormat se ela passar de maxFormatWidth
+func formatSize(spec, name, digits string) int {
+	n, err := strconv.Atoi(digits)
+	if err != nil || n > maxFormatWidth {
+		throw(ErrFormat, "Especificação de formato inválida: %s (%s maior que %d)", spec, name, maxFormatWidth)
+	}
+	return n
+}
+
+// formatValue converte um valor para texto segundo a especificação de formato.
+// Precisão e largura são contadas no limite de memória antes de gerar o
+// texto, já que 💱{x:.999999999f} alocaria muito a partir de pouco código.
+func formatValue(env *Env, value interface{}, spec string) string {
+	if spec == "" {
+		return Stringify(value)
+	}
+	f := parseFormatSpec(spec)
+	env.Alloc(max(f.precision, 0))
+
+	var text string
+	number, isNumber := value.(int)
+	switch f.verb {
+	case 'd', 'x', 'X', 'o', 'b':
+		if !isNumber {
+			throw(ErrFormat, "Erro de formato: %s requer um valor do tipo NUMBER", spec)
+		}
+		base := map[byte]int{'d': 10, 'x': 16, 'X': 16, 'o': 8, 'b': 2}[f.verb]
+		text = strconv.FormatInt(int64(number), base)
+		if f.verb == 'X' {
+			text = strings.ToUpper(text)
+		}
+	case 'f', 'e':
+		if !isNumber {
+			throw(ErrFormat, "Erro de formato: %s requer um valor do tipo NUMBER", spec)
+		}
+		precision := f.precision
+		if precision < 0 {
+			precision = 6
+		}
+		text = strconv.FormatFloat(float64(number), f.verb, precision, 64)
+	default:
+		if isNumber && f.precision >= 0 {
+			// Precisão em um número sem tipo explícito equivale a 'f'
+			text = strconv.FormatFloat(float64(number), 'f', f.precision, 64)
+			break
+		}
+		text = Stringify(value)
+		if f.precision >= 0 && utf8.RuneCountInString(text) > f.precision {
+			text = string([]rune(text)[:f.precision])
+		}
+	}
+
+	if missing := f.width - utf8.RuneCountInString(text); missing > 0 {
+		fill := max(utf8.RuneLen(f.fill), 1)
+		if missing > math.MaxInt/fill {
+			throw(ErrResource, "Limite de memória excedido: preenchimento de %d caracteres é grande demais", missing)
+		}
+		env.Alloc(missing * fill)
+	}
+	return f.pad(text, isNumber && f.verb != 's')
+}
+
+// pad completa o texto até a largura mínima. Números são alinhados à direita
+// e textos à esquerda, a menos que outro alinhamento seja informado.
+func (f formatSpec) pad(text string, numeric bool) string {
+	missing := f.width - utf8.RuneCountInString(text)
+	if missing <= 0 {
+		return text
+	}
+
+	if f.zero && f.align == 0 && numeric {
+		// Zeros à esquerda vêm depois do sinal: -0042
+		sign := ""
+		if strings.HasPrefix(text, "-") {
+			sign, text = "-", text[1:]
+		}
+		return sign + strings.Repeat("0", missing) + text
+	}
+
+	fill := string(f.fill)
+	if f.zero && f.align != 0 {
+		fill = "0"
+	}
+
+	align := f.align
+	if align == 0 {
+		align = '<'
+		if numeric {
+			align = '>'
+		}
+	}
+
+	switch align {
+	case '>':
+		return strings.Repeat(fill, missing) + text
+	case '^':
+		left := missing / 2
+		return strings.Repeat(fill, left) + text + strings.Repeat(fill, missing-left)
+	default:
+		return text + strings.Repeat(fill, missing)
+	}
+}
@@ -0,0 +1,85 @@
+package interpreter
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"melhorzin-lang/internal/lexer"
+	"melhorzin-lang/internal/parser"
+	"testing"
+)
+
+// run executa source em um interpretador com as capacidades caps e retorna o
+// que ele imprimiu
+func run(t *testing.T, caps Capabilities, source string) (string, error) {
+	t.Helper()
+	return interpret(t, NewInterpreter(caps), source)
+}
+
+// interpret executa source em interp e retorna o que ele imprimiu
+func interpret(t *testing.T, interp *Interpreter, source string) (string, error) {
+	t.Helper()
+	var out bytes.Buffer
+	interp.SetIO(nil, &out, &out)
+	nodes := parser.NewParserWithTypes(lexer.NewLexer(source), interp.Types()).Parse()
+	_, err := interp.Interpret(context.Background(), nodes)
+	return out.String(), err
+}
+
+func TestFormatSpec(t *testing.T) {
+	tests := []struct {
+		expr string
+		want string
+	}{
+		{`💱{42}`, "42"},
+		{`💱{42:5}`, "   42"},
+		{`💱{42:<5}`, "42   "},
+		{`💱{42:05}`, "00042"},
+		{`💱{-42:05}`, "-0042"},
+		{`💱{255:x}`, "ff"},
+		{`💱{255:08X}`, "000000FF"},
+		{`💱{5:b}`, "101"},
+		{`💱{8:o}`, "10"},
+		{`💱{3:.2f}`, "3.00"},
+		{`💱{3:.2}`, "3.00"},
+		{`💱{"ab":>4}`, "  ab"},
+		{`💱{"ab":*^6}`, "**ab**"},
+		{`💱{"ab":😀<4}`, "ab😀😀"},
+		{`💱{"abcdef":.3}`, "abc"},
+		{`💱{"olá":5s}`, "olá  "},
+		{`💱{7:>>3}`, ">>7"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			got, err := run(t, Capabilities{Stdout: true}, "🖨️ \""+tt.expr+"\"")
+			if err != nil {
+				t.Fatalf("erro inesperado %v", err)
+			}
+			if got != tt.want+"\n" {
+				t.Errorf("%s = %q, esperado %q", tt.expr, got, tt.want+"\n")
+			}
+		})
+	}
+}
+
+func TestFormatSpecErrors(t *testing.T) {
+	for _, expr := range []string{
+		`💱{1:.}`,
+		`💱{1:z}`,
+		`💱{"a":d}`,
+		`💱{"a":.2f}`,
+		`💱{1:1000001}`,
+		`💱{1:99999999999999999999}`,
+		`💱{1:.1000001}`,
+		`💱{1:.99999999999999999999}`,
+		`💱{1:😀>99999999999999999999}`,
+	} {
+		t.Run(expr, func(t *testing.T) {
+			_, err := run(t, Capabilities{Stdout: true}, "🖨️ \""+expr+"\"")
+			var runtimeErr *RuntimeError
+			if !errors.As(err, &runtimeErr) || runtimeErr.Kind != ErrFormat {
+				t.Errorf("%s: erro %v, esperado erro do tipo %s", expr, err, ErrFormat)
+			}
+		})
+	}
+}
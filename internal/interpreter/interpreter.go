@@ -1,62 +1,129 @@
 package interpreter
 
 import (
+	"context"
 	"fmt"
-	"melhorzin-lang/internal/parser"
+	"io"
+	"maps"
+	"melhorzin-lang/internal/ast"
 )
 
 // Interpreter executa a AST.
 type Interpreter struct {
-	variables map[string]interface{}
-	types     map[string]parser.Type // Mapa para guardar os tipos das variáveis
-	result    interface{}
+	env    *Env
+	types  map[string]ast.Type // Mapa para guardar os tipos das variáveis
+	result interface{}
+	echo   bool // Imprimir o resultado de expressões no nível superior
 }
 
-// NewInterpreter cria um novo interpretador.
-func NewInterpreter() *Interpreter {
+// NewInterpreter cria um novo interpretador, ligado à entrada e às saídas
+// padrão do processo, com as funções nativas da linguagem. caps define o que
+// o programa pode fazer fora do interpretador: Sandbox() para um sandbox
+// puro, Trusted() para um programa confiável.
+func NewInterpreter(caps Capabilities) *Interpreter {
+	runtime := NewStdRuntime()
+	runtime.Caps = caps
+	env := NewEnv(runtime)
+	RegisterBuiltins(env)
 	return &Interpreter{
-		variables: make(map[string]interface{}),
-		types:     make(map[string]parser.Type),
+		env:   env,
+		types: make(map[string]ast.Type),
+		echo:  true,
 	}
 }
 
-// Interpret executa os nós da AST.
-func (i *Interpreter) Interpret(nodes []parser.Node) interface{} {
+// SetEcho define se o valor de expressões no nível superior (soma(1, 2),
+// x 🟰 3) é impresso. O padrão é imprimir; sem a capacidade Stdout, o eco é
+// omitido, como tudo o que o programa imprimiria.
+func (i *Interpreter) SetEcho(echo bool) {
+	i.echo = echo
+}
+
+// SetIO troca a entrada e as saídas do programa. Saídas nil descartam o que é
+// escrito e uma entrada nil está sempre no fim.
+func (i *Interpreter) SetIO(stdin io.Reader, stdout, stderr io.Writer) {
+	i.env.SetIO(stdin, stdout, stderr)
+}
+
+// SetMaxSteps limita quantos nós cada execução (Interpret ou Call) pode
+// avaliar; 0 significa sem limite.
+func (i *Interpreter) SetMaxSteps(steps int) {
+	i.env.MaxSteps = steps
+}
+
+// SetMaxAlloc limita quantos bytes de strings cada execução pode alocar, no
+// total; 0 significa sem limite. Ao exceder o limite o programa recebe um
+// erro de execução ErrResource, que pode ser capturado.
+func (i *Interpreter) SetMaxAlloc(bytes int) {
+	i.env.MaxAlloc = bytes
+}
+
+// SetMaxDepth limita quantas chamadas de função podem estar aninhadas; 0
+// significa DefaultMaxDepth. Uma recursão mais funda recebe um erro de
+// execução ErrResource, que pode ser capturado.
+func (i *Interpreter) SetMaxDepth(depth int) {
+	i.env.MaxDepth = depth
+}
+
+// Interpret executa os nós da AST e retorna o valor da última instrução.
+//
+// Um erro de execução não capturado pelo programa é retornado como
+// *RuntimeError. Se ctx for cancelado ou o limite de passos excedido, a
+// execução para e o erro é um *Interrupt, que pode ser testado com
+// errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded)
+// ou errors.Is(err, ErrStepLimit). Se o programa chamar sair, o erro é um
+// *Exit com o código pedido.
+func (i *Interpreter) Interpret(ctx context.Context, nodes []ast.Node) (result interface{}, err error) {
 	i.result = nil
+	err = i.run(ctx, func() {
+		i.interpret(nodes)
+	})
+	return i.result, err
+}
 
+func (i *Interpreter) interpret(nodes []ast.Node) {
 	for _, node := range nodes {
-		result := node.Evaluate(i.variables)
+		result := eval(i.env, node)
 		i.result = result
 
 		// Se for um nó de atribuição, armazenar o tipo da variável
-		if assignNode, ok := node.(*parser.AssignNode); ok {
+		if assignNode, ok := node.(*ast.AssignNode); ok {
 			i.types[assignNode.Name] = assignNode.GetType()
 		}
 
 		// Remover prints duplicados - o PrintNode já imprime diretamente
 		// Apenas mostrar outros tipos de resultados
-		if result != nil {
-			if _, ok := node.(*parser.PrintNode); !ok {
-				switch v := result.(type) {
-				case int:
-					fmt.Println(v)
-				case bool:
-					fmt.Println(v)
-				case string:
-					fmt.Println(v)
-				case *parser.FunctionNode:
+		if result != nil && i.echo && i.env.Caps.Stdout {
+			if _, ok := node.(*ast.PrintNode); !ok {
+				if _, ok := result.(*ast.FunctionNode); !ok {
 					// Não exibe nada quando define uma função
-				default:
-					// Se o resultado for um valor de retorno de chamada de função, exibe
-					if _, ok := node.(*parser.FunctionCallNode); ok {
-						// Não imprimir novamente, o valor já foi impresso na função
-					}
+					fmt.Fprintln(i.env.Stdout, Stringify(result))
 				}
 			}
 		}
 	}
+}
 
-	return i.result
+// run executa fn como uma execução do programa, convertendo erros de
+// execução e interrupções em error
+func (i *Interpreter) run(ctx context.Context, fn func()) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			switch e := r.(type) {
+			case *RuntimeError:
+				err = e
+			case *Interrupt:
+				err = e
+			case *Exit:
+				err = e
+			default:
+				panic(r)
+			}
+		}
+	}()
+	i.env.Start(ctx)
+	fn()
+	return nil
 }
 
 // GetResult retorna o último valor calculado
@@ -65,9 +132,41 @@ func (i *Interpreter) GetResult() interface{} {
 }
 
 // GetVariableType retorna o tipo de uma variável
-func (i *Interpreter) GetVariableType(name string) parser.Type {
+func (i *Interpreter) GetVariableType(name string) ast.Type {
 	if t, exists := i.types[name]; exists {
 		return t
 	}
-	return parser.TypeAny
+	return ast.TypeAny
+}
+
+// Types retorna os tipos conhecidos das variáveis, para ser compartilhado com
+// o parser (veja parser.NewParserWithTypes) ao executar um programa em partes
+func (i *Interpreter) Types() map[string]ast.Type {
+	return i.types
+}
+
+// GetVariable retorna o valor de uma variável global
+func (i *Interpreter) GetVariable(name string) (interface{}, bool) {
+	value, exists := i.env.Vars[name]
+	return value, exists
+}
+
+// Variables retorna uma cópia das variáveis globais, incluindo funções
+func (i *Interpreter) Variables() map[string]interface{} {
+	return maps.Clone(i.env.Vars)
+}
+
+// SetVariable define uma variável global com um valor da linguagem
+func (i *Interpreter) SetVariable(name string, value interface{}) {
+	i.env.Vars[name] = value
+	i.types[name] = TypeOfValue(value)
+}
+
+// Call chama uma função global com argumentos já convertidos para valores da
+// linguagem. Os erros são os mesmos de Interpret.
+func (i *Interpreter) Call(ctx context.Context, name string, args []interface{}) (result interface{}, err error) {
+	err = i.run(ctx, func() {
+		result = CallFunction(i.env, name, args)
+	})
+	return result, err
 }
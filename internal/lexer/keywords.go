@@ -0,0 +1,327 @@
+package lexer
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// Keyword associa a grafia de uma palavra-chave ou operador ao tipo de token
+// que ela produz.
+type Keyword struct {
+	Word string
+	Type TokenType
+}
+
+// defaultKeywords é a tabela padrão da linguagem: palavras-chave em emoji,
+// palavras reservadas e operadores.
+var defaultKeywords = []Keyword{
+	{"🖨️", TokenPrint},
+	{"✍️", TokenAssign},
+	{"🟰", TokenEqual},
+	{"👨🏿‍💻", TokenTry},
+	{"🤦🏿‍♂️", TokenCatch},
+	{"🚀", TokenTryStart},
+	{"▶️", TokenFunction},
+	{"↩️", TokenReturn},
+	{"⌨️", TokenInput},
+	{"✖️", TokenMult},
+	{"➕", TokenNumPlus},
+	{"🤝", TokenAnd},
+	{"🔀", TokenOr},
+	{"🚫", TokenNot},
+	{"💱", TokenInterpolate},
+	{"🔢", TokenTypeNumber},
+	{"📝", TokenTypeString},
+	{"⚖️", TokenTypeBool},
+	{"🗑️", TokenTypeAny},
+
+	{"main", TokenMain},
+
+	{"=", TokenEqualSign},
+	{"==", TokenEqual},
+	{"+", TokenPlus},
+	{"+=", TokenPlusAssign},
+	{"++", TokenIncrement},
+	{"-", TokenMinus},
+	{"-=", TokenMinusAssign},
+	{"--", TokenDecrement},
+	{"*", TokenMult},
+	{"*=", TokenMultAssign},
+	{".", TokenConcat},
+	{".=", TokenConcatAssign},
+	{"&", TokenBitAnd},
+	{"|", TokenBitOr},
+	{"^", TokenBitXor},
+	{"<<", TokenShiftLeft},
+	{">>", TokenShiftRight},
+}
+
+// textKeywords é a superfície textual oficial, para teclados e terminais sem
+// emojis. Fica fora da tabela padrão para que programas existentes possam
+// continuar usando essas palavras como identificadores (veja TextKeywords).
+var textKeywords = []Keyword{
+	{"print", TokenPrint},
+	{"let", TokenAssign},
+	{"try", TokenTry},
+	{"catch", TokenCatch},
+	{"start", TokenTryStart},
+	{"fn", TokenFunction},
+	{"return", TokenReturn},
+	{"input", TokenInput},
+	{"and", TokenAnd},
+	{"or", TokenOr},
+	{"not", TokenNot},
+	{"num", TokenTypeNumber},
+	{"str", TokenTypeString},
+	{"bool", TokenTypeBool},
+	{"any", TokenTypeAny},
+}
+
+// emojiForms é a grafia canônica em emoji de cada palavra-chave que tem uma
+// forma textual.
+var emojiForms = map[TokenType]string{
+	TokenPrint:      "🖨️",
+	TokenAssign:     "✍️",
+	TokenEqual:      "🟰",
+	TokenTry:        "👨🏿‍💻",
+	TokenCatch:      "🤦🏿‍♂️",
+	TokenTryStart:   "🚀",
+	TokenFunction:   "▶️",
+	TokenReturn:     "↩️",
+	TokenInput:      "⌨️",
+	TokenMult:       "✖️",
+	TokenAnd:        "🤝",
+	TokenOr:         "🔀",
+	TokenNot:        "🚫",
+	TokenTypeNumber: "🔢",
+	TokenTypeString: "📝",
+	TokenTypeBool:   "⚖️",
+	TokenTypeAny:    "🗑️",
+}
+
+// textForms é a grafia textual de cada palavra-chave que tem uma forma em
+// emoji. ✖️ é escrito como o operador *, que produz o mesmo token; ➕ não tem
+// forma textual, pois + é outro token (PLUS), e fica como está.
+var textForms = map[TokenType]string{
+	TokenPrint:      "print",
+	TokenAssign:     "let",
+	TokenEqual:      "==",
+	TokenTry:        "try",
+	TokenCatch:      "catch",
+	TokenTryStart:   "start",
+	TokenFunction:   "fn",
+	TokenReturn:     "return",
+	TokenInput:      "input",
+	TokenMult:       "*",
+	TokenAnd:        "and",
+	TokenOr:         "or",
+	TokenNot:        "not",
+	TokenTypeNumber: "num",
+	TokenTypeString: "str",
+	TokenTypeBool:   "bool",
+	TokenTypeAny:    "any",
+}
+
+// EmojiForm retorna a grafia canônica em emoji de uma palavra-chave, ou false
+// se o tipo de token não tem forma em emoji.
+func EmojiForm(typ TokenType) (string, bool) {
+	form, ok := emojiForms[typ]
+	return form, ok
+}
+
+// TextForm retorna a grafia textual de uma palavra-chave, ou false se o tipo
+// de token não tem forma textual.
+func TextForm(typ TokenType) (string, bool) {
+	form, ok := textForms[typ]
+	return form, ok
+}
+
+// EmojiKeywords retorna a forma em emoji de cada palavra-chave que também tem
+// uma forma textual, indexada pela forma textual (print → 🖨️).
+func EmojiKeywords() map[string]string {
+	forms := make(map[string]string, len(textForms))
+	for typ, text := range textForms {
+		if emoji, ok := emojiForms[typ]; ok {
+			forms[text] = emoji
+		}
+	}
+	return forms
+}
+
+// Keywords é um dicionário de palavras-chave e operadores, consultado pelo
+// lexer com a maior correspondência possível (longest match) através de uma
+// trie. Pode ser estendido em tempo de execução com aliases (print, let, fn)
+// ou conjuntos de palavras-chave localizados.
+type Keywords struct {
+	root *trieNode
+}
+
+type trieNode struct {
+	children map[rune]*trieNode
+	typ      TokenType // vazio se nenhuma palavra termina neste nó
+}
+
+// NewKeywords cria um dicionário vazio.
+func NewKeywords() *Keywords {
+	return &Keywords{root: &trieNode{}}
+}
+
+// DefaultKeywords cria um dicionário com a tabela padrão da linguagem. Cada
+// chamada retorna uma cópia independente, que pode ser estendida livremente.
+func DefaultKeywords() *Keywords {
+	k := NewKeywords()
+	for _, kw := range defaultKeywords {
+		k.Add(kw.Word, kw.Type)
+	}
+	return k
+}
+
+// TextKeywords cria um dicionário com a tabela padrão e a superfície textual
+// (print, let, fn, ...). Com ele essas palavras deixam de ser identificadores.
+func TextKeywords() *Keywords {
+	k := DefaultKeywords()
+	for _, kw := range textKeywords {
+		k.Add(kw.Word, kw.Type)
+	}
+	return k
+}
+
+// IsTextKeyword indica se word é uma palavra-chave da superfície textual.
+func IsTextKeyword(word string) bool {
+	for _, kw := range textKeywords {
+		if kw.Word == word {
+			return true
+		}
+	}
+	return false
+}
+
+// Add registra uma palavra-chave. Modificadores de emoji (variation selector
+// e tom de pele) são ignorados, pois o lexer também os ignora ao comparar. Se
+// a palavra já existir, o tipo é substituído.
+func (k *Keywords) Add(word string, typ TokenType) {
+	node := k.root
+	for _, r := range word {
+		if isEmojiModifier(r) {
+			continue
+		}
+		if node.children == nil {
+			node.children = make(map[rune]*trieNode)
+		}
+		child, exists := node.children[r]
+		if !exists {
+			child = &trieNode{}
+			node.children[r] = child
+		}
+		node = child
+	}
+	if node != k.root {
+		node.typ = typ
+	}
+}
+
+// Match procura a maior palavra-chave no início de input e retorna seu tipo e
+// quantos bytes do input ela ocupa (0 se nenhuma corresponder).
+//
+// Modificadores de emoji no input são ignorados, então 🖨 e 🖨️ são a mesma
+// palavra-chave, e 👨🏿‍💻, 👨🏻‍💻 e 👨‍💻 também. Os demais componentes de uma
+// sequência ZWJ são significativos: 🤦‍♀️ não é 🤦🏿‍♂️, e um emoji só
+// corresponde se o grapheme cluster terminar junto com ele (👨‍💻‍🔧 não é
+// 👨‍💻). Palavras terminadas em letra só correspondem em fim de palavra, para
+// que "andar" seja um identificador e não "and" seguido de "ar".
+func (k *Keywords) Match(input string) (TokenType, int) {
+	var (
+		matchType TokenType
+		matchLen  int
+		node      = k.root
+		i         = 0
+	)
+	for {
+		i = skipEmojiModifiers(input, i)
+		r, size := utf8.DecodeRuneInString(input[i:])
+		if size == 0 {
+			break
+		}
+		child, exists := node.children[r]
+		if !exists {
+			break
+		}
+		node = child
+		i += size
+
+		if node.typ == "" {
+			continue
+		}
+		end := skipEmojiModifiers(input, i)
+		next, _ := utf8.DecodeRuneInString(input[end:])
+		if next == zeroWidthJoiner {
+			continue
+		}
+		if isIdentifierPart(r) && isIdentifierPart(next) {
+			continue
+		}
+		matchType, matchLen = node.typ, end
+	}
+	return matchType, matchLen
+}
+
+// Load lê palavras-chave adicionais de r, no formato "palavra TIPO" por
+// linha, em que TIPO é o nome de um tipo de token (PRINT, ASSIGN, FUNCTION,
+// ...). Linhas vazias e iniciadas por # são ignoradas.
+//
+//	# aliases em inglês
+//	print PRINT
+//	let   ASSIGN
+//	fn    FUNCTION
+func (k *Keywords) Load(r io.Reader) error {
+	scanner := bufio.NewScanner(r)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		fields := strings.Fields(text)
+		if len(fields) != 2 {
+			return fmt.Errorf("linha %d: esperado \"palavra TIPO\", encontrado %q", line, text)
+		}
+		typ := TokenType(fields[1])
+		if !keywordTypes[typ] {
+			return fmt.Errorf("linha %d: tipo de token desconhecido %s", line, fields[1])
+		}
+		k.Add(fields[0], typ)
+	}
+	return scanner.Err()
+}
+
+// LoadKeywords cria um dicionário com a tabela padrão estendida pelas
+// palavras-chave lidas de r (veja Keywords.Load).
+func LoadKeywords(r io.Reader) (*Keywords, error) {
+	k := DefaultKeywords()
+	if err := k.Load(r); err != nil {
+		return nil, err
+	}
+	return k, nil
+}
+
+// keywordTypes são os tipos de token que podem ser associados a uma
+// palavra-chave. Literais, identificadores e pontuação com tratamento
+// especial no lexer ({, }, :, strings) ficam de fora.
+var keywordTypes = map[TokenType]bool{
+	TokenPrint: true, TokenAssign: true, TokenEqual: true, TokenMain: true,
+	TokenTry: true, TokenCatch: true, TokenTryStart: true, TokenFunction: true,
+	TokenReturn: true, TokenInput: true, TokenInterpolate: true, TokenMult: true, TokenNumPlus: true,
+	TokenConcat: true, TokenLParen: true, TokenRParen: true, TokenComma: true,
+	TokenEqualSign: true, TokenPlus: true, TokenMinus: true,
+	TokenTypeNumber: true, TokenTypeString: true, TokenTypeBool: true, TokenTypeAny: true,
+	TokenPlusAssign: true, TokenMinusAssign: true, TokenMultAssign: true,
+	TokenConcatAssign: true, TokenIncrement: true, TokenDecrement: true,
+	TokenAnd: true, TokenOr: true, TokenNot: true,
+	TokenBitAnd: true, TokenBitOr: true, TokenBitXor: true,
+	TokenShiftLeft: true, TokenShiftRight: true,
+}
@@ -0,0 +1,94 @@
+package lexer
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestKeywordsMatch(t *testing.T) {
+	k := NewKeywords()
+	k.Add("=", TokenEqualSign)
+	k.Add("==", TokenEqual)
+	k.Add("and", TokenAnd)
+	k.Add("🖨️", TokenPrint)
+
+	tests := []struct {
+		input string
+		typ   TokenType
+		n     int
+	}{
+		{"= 1", TokenEqualSign, 1},
+		{"== 1", TokenEqual, 2},  // Maior correspondência
+		{"=== 1", TokenEqual, 2}, // O resto fica para o próximo token
+		{"and x", TokenAnd, 3},   // Palavra seguida de espaço
+		{"and(", TokenAnd, 3},    // ... ou de pontuação
+		{"andar", "", 0},         // Prefixo de um identificador
+		{"🖨 x", TokenPrint, 4},   // Sem variation selector
+		{"🖨️x", TokenPrint, 7},   // O variation selector faz parte do token
+		{"x = 1", "", 0},
+		{"", "", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			if typ, n := k.Match(tt.input); typ != tt.typ || n != tt.n {
+				t.Errorf("Match(%q) = %s, %d; esperado %s, %d", tt.input, typ, n, tt.typ, tt.n)
+			}
+		})
+	}
+}
+
+func TestKeywordsAdd(t *testing.T) {
+	k := DefaultKeywords()
+	k.Add("imprima", TokenPrint)
+	if typ, _ := k.Match("imprima 1"); typ != TokenPrint {
+		t.Errorf("alias imprima = %s, esperado %s", typ, TokenPrint)
+	}
+
+	// Substituir o tipo de uma palavra existente
+	k.Add("imprima", TokenReturn)
+	if typ, _ := k.Match("imprima 1"); typ != TokenReturn {
+		t.Errorf("alias substituído = %s, esperado %s", typ, TokenReturn)
+	}
+
+	// Cada chamada de DefaultKeywords é independente
+	if typ, _ := DefaultKeywords().Match("imprima"); typ != "" {
+		t.Errorf("alias vazou para a tabela padrão: %s", typ)
+	}
+
+	// Palavras-chave textuais só existem em TextKeywords
+	if typ, _ := DefaultKeywords().Match("print x"); typ != "" {
+		t.Errorf("print na tabela padrão = %s, esperado identificador", typ)
+	}
+	if typ, _ := TextKeywords().Match("print x"); typ != TokenPrint {
+		t.Errorf("print em TextKeywords = %s, esperado %s", typ, TokenPrint)
+	}
+}
+
+func TestLoadKeywords(t *testing.T) {
+	k, err := LoadKeywords(strings.NewReader("# aliases\n\nimprima PRINT\n  seja   ASSIGN  \n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tokens := NewLexerWithKeywords("seja x = 1 imprima x", k).Lex()
+	if tokens[0].Type != TokenAssign || tokens[4].Type != TokenPrint {
+		t.Errorf("tokens = %v, esperado ASSIGN ... PRINT", tokens)
+	}
+
+	errors := []struct {
+		name  string
+		input string
+		err   string // Trecho da mensagem de erro esperada
+	}{
+		{"tipo desconhecido", "imprima IMPRIMIR", "linha 1: tipo de token desconhecido IMPRIMIR"},
+		{"tipo não permitido", "nome IDENTIFIER", "tipo de token desconhecido IDENTIFIER"},
+		{"campos demais", "# ok\na b c", "linha 2: esperado"},
+		{"sem tipo", "imprima", "linha 1: esperado"},
+	}
+	for _, tt := range errors {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := LoadKeywords(strings.NewReader(tt.input)); err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("LoadKeywords(%q) = %v, esperado erro com %q", tt.input, err, tt.err)
+			}
+		})
+	}
+}
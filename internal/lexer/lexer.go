@@ -1,9 +1,15 @@
 package lexer
 
 import (
+	"bufio"
 	"fmt"
+	"io"
+	"iter"
+	"os"
+	"strconv"
 	"strings"
 	"unicode"
+	"unicode/utf8"
 )
 
 // TokenType define os tipos de tokens.
@@ -12,20 +18,22 @@ type TokenType string
 const (
 	TokenPrint       TokenType = "PRINT"       // 🖨️
 	TokenAssign      TokenType = "ASSIGN"      // ✍️
-	TokenEqual       TokenType = "EQUAL"       // 🟰
+	TokenEqual       TokenType = "EQUAL"       // 🟰 / ==
 	TokenMain        TokenType = "MAIN"        // main
 	TokenTry         TokenType = "TRY"         // 👨🏿‍💻
 	TokenCatch       TokenType = "CATCH"       // 🤦🏿‍♂️
 	TokenTryStart    TokenType = "TRY_START"   // 🚀
 	TokenFunction    TokenType = "FUNCTION"    // ▶️
 	TokenReturn      TokenType = "RETURN"      // ↩️
+	TokenInput       TokenType = "INPUT"       // ⌨️
 	TokenInterpolate TokenType = "INTERPOLATE" // 💱
+	TokenFormatSpec  TokenType = "FORMAT_SPEC" // .2f em 💱{preco:.2f}
 	TokenMult        TokenType = "MULT"        // ✖️
 	TokenNumPlus     TokenType = "NUMPLUS"     // ➕
 	TokenConcat      TokenType = "CONCAT"      // .
 	TokenIdentifier  TokenType = "IDENTIFIER"  // nomedavariavel
 	TokenString      TokenType = "STRING"      // "texto"
-	TokenNumber      TokenType = "NUMBER"      // 10
+	TokenNumber      TokenType = "NUMBER"      // 10, 1_000, 0xFF, 0b1010, 0o755
 	TokenBoolean     TokenType = "BOOLEAN"     // true/false
 	TokenLBrace      TokenType = "LBRACE"      // {
 	TokenRBrace      TokenType = "RBRACE"      // }
@@ -34,6 +42,7 @@ const (
 	TokenComma       TokenType = "COMMA"       // ,
 	TokenEqualSign   TokenType = "EQUALSIGN"   // =
 	TokenPlus        TokenType = "PLUS"        // +
+	TokenMinus       TokenType = "MINUS"       // -
 	TokenEOF         TokenType = "EOF"
 
 	// Tokens for type system
@@ -42,236 +51,630 @@ const (
 	TokenTypeBool   TokenType = "TYPE_BOOL"   // ⚖️
 	TokenTypeAny    TokenType = "TYPE_ANY"    // 🗑️
 	TokenTypeColon  TokenType = "TYPE_COLON"  // :
+
+	// Tokens de atribuição composta
+	TokenPlusAssign   TokenType = "PLUS_ASSIGN"   // +=
+	TokenMinusAssign  TokenType = "MINUS_ASSIGN"  // -=
+	TokenMultAssign   TokenType = "MULT_ASSIGN"   // *=
+	TokenConcatAssign TokenType = "CONCAT_ASSIGN" // .=
+	TokenIncrement    TokenType = "INCREMENT"     // ++
+	TokenDecrement    TokenType = "DECREMENT"     // --
+
+	// Operadores lógicos
+	TokenAnd TokenType = "AND" // and / 🤝
+	TokenOr  TokenType = "OR"  // or / 🔀
+	TokenNot TokenType = "NOT" // not / 🚫
+
+	// Operadores bit a bit
+	TokenBitAnd     TokenType = "BIT_AND"     // &
+	TokenBitOr      TokenType = "BIT_OR"      // |
+	TokenBitXor     TokenType = "BIT_XOR"     // ^
+	TokenShiftLeft  TokenType = "SHIFT_LEFT"  // <<
+	TokenShiftRight TokenType = "SHIFT_RIGHT" // >>
 )
 
 // Token representa um token com tipo e valor.
 type Token struct {
 	Type  TokenType
 	Value string
+	Pos   int // Posição (em bytes) do token no código fonte
+	End   int // Posição logo depois do token; strings incluem as aspas
 }
 
-// Lexer contém o estado do lexer.
+// Lexer contém o estado do lexer. Os tokens são produzidos sob demanda por
+// NextToken, lendo o código de uma string ou de um io.Reader.
 type Lexer struct {
-	input  string
-	pos    int
-	tokens []Token
+	input    string        // Trecho do código já lido e ainda não descartado
+	pos      int           // Posição atual em input
+	base     int           // Posição de input[0] no código fonte
+	reader   *bufio.Reader // Origem de mais código; nil quando esgotada
+	tokens   []Token       // Tokens produzidos e ainda não consumidos
+	keywords *Keywords
+	done     bool      // O TokenEOF já foi produzido
+	failed   bool      // Erro que impede continuar (string não terminada)
+	errors   io.Writer // Onde os erros léxicos são escritos
+	errs     []*Error  // Erros léxicos encontrados
+	// Pilha de interpolações abertas (💱{ ... }) dentro de strings
+	interpolations []interpolation
 }
 
-// NewLexer cria um novo lexer.
+// interpolation guarda o estado de uma interpolação aberta: quantas chaves {
+// estão abertas dentro da expressão e qual delimitador fecha a string em que
+// ela aparece.
+type interpolation struct {
+	braces int
+	quote  string
+}
+
+// NewLexer cria um novo lexer com a tabela padrão de palavras-chave.
 func NewLexer(input string) *Lexer {
-	return &Lexer{input: input, pos: 0, tokens: []Token{}}
+	return NewLexerWithKeywords(input, DefaultKeywords())
+}
+
+// NewLexerWithKeywords cria um novo lexer que reconhece as palavras-chave do
+// dicionário informado.
+func NewLexerWithKeywords(input string, keywords *Keywords) *Lexer {
+	return &Lexer{input: input, pos: 0, tokens: []Token{}, keywords: keywords, errors: os.Stderr}
+}
+
+// SetErrorOutput define onde os erros léxicos são escritos. O padrão é a
+// saída de erros do processo, para que não se misturem à saída do programa;
+// com io.Discard, eles continuam disponíveis em Errors.
+func (l *Lexer) SetErrorOutput(w io.Writer) {
+	l.errors = w
+}
+
+// Error é um erro léxico: um trecho do código que não forma um token válido.
+type Error struct {
+	Pos     int // Posição (em bytes) do erro no código fonte
+	Message string
 }
 
-// Lex analisa o input e retorna os tokens.
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s na posição %d", e.Message, e.Pos)
+}
+
+// Errors retorna os erros léxicos encontrados até agora, em ordem.
+func (l *Lexer) Errors() []*Error {
+	return l.errs
+}
+
+// errorf registra um erro léxico na posição pos e o escreve na saída de erros
+func (l *Lexer) errorf(pos int, format string, args ...interface{}) {
+	err := &Error{Pos: pos, Message: fmt.Sprintf(format, args...)}
+	l.errs = append(l.errs, err)
+	fmt.Fprintln(l.errors, err)
+}
+
+// NewReaderLexer cria um lexer que lê o código de r à medida que os tokens
+// são pedidos, com a tabela padrão de palavras-chave.
+func NewReaderLexer(r io.Reader) *Lexer {
+	return NewReaderLexerWithKeywords(r, DefaultKeywords())
+}
+
+// NewReaderLexerWithKeywords cria um lexer que lê o código de r à medida que
+// os tokens são pedidos, reconhecendo as palavras-chave do dicionário
+// informado.
+func NewReaderLexerWithKeywords(r io.Reader, keywords *Keywords) *Lexer {
+	l := NewLexerWithKeywords("", keywords)
+	l.reader = bufio.NewReader(r)
+	return l
+}
+
+// Lex analisa todo o input e retorna os tokens, terminando com TokenEOF.
 func (l *Lexer) Lex() []Token {
-	for l.pos < len(l.input) {
-		// Ler a próxima sequência de bytes como string para comparar emojis
-		remaining := l.input[l.pos:]
+	var tokens []Token
+	for {
+		token := l.NextToken()
+		tokens = append(tokens, token)
+		if token.Type == TokenEOF {
+			return tokens
+		}
+	}
+}
 
-		// Verificar emojis primeiro
-		if strings.HasPrefix(remaining, "🖨️") {
-			l.tokens = append(l.tokens, Token{Type: TokenPrint, Value: "🖨️"})
-			l.pos += len("🖨️")
-			continue
+// NextToken consome e retorna o próximo token. Depois do fim do input,
+// retorna TokenEOF indefinidamente.
+func (l *Lexer) NextToken() Token {
+	token := l.Peek(0)
+	if len(l.tokens) > 0 {
+		l.tokens = l.tokens[1:]
+	}
+	return token
+}
+
+// Peek retorna o token n posições à frente sem consumi-lo (Peek(0) é o
+// próximo token a ser retornado por NextToken).
+func (l *Lexer) Peek(n int) Token {
+	for len(l.tokens) <= n && !l.done {
+		if !l.step() {
+			l.finish()
 		}
-		if strings.HasPrefix(remaining, "✍️") {
-			l.tokens = append(l.tokens, Token{Type: TokenAssign, Value: "✍️"})
-			l.pos += len("✍️")
-			continue
+	}
+	if n < len(l.tokens) {
+		return l.tokens[n]
+	}
+	return Token{Type: TokenEOF, Value: "", Pos: l.base + l.pos}
+}
+
+// Tokens retorna um iterador sobre os tokens restantes, incluindo o
+// TokenEOF final.
+func (l *Lexer) Tokens() iter.Seq[Token] {
+	return func(yield func(Token) bool) {
+		for {
+			token := l.NextToken()
+			if !yield(token) || token.Type == TokenEOF {
+				return
+			}
 		}
-		if strings.HasPrefix(remaining, "🟰") {
-			l.tokens = append(l.tokens, Token{Type: TokenEqual, Value: "🟰"})
-			l.pos += len("🟰")
-			continue
+	}
+}
+
+// Incomplete indica se o input acabou no meio de uma string ou de uma
+// interpolação, ou seja, se mais código poderia completá-lo. Só faz sentido
+// depois que o TokenEOF foi produzido.
+func (l *Lexer) Incomplete() bool {
+	return l.failed || len(l.interpolations) > 0
+}
+
+// finish produz o TokenEOF ao fim do input.
+func (l *Lexer) finish() {
+	if len(l.interpolations) > 0 && !l.failed {
+		l.errorf(l.base+l.pos, "Interpolação não terminada")
+	}
+	l.emit(TokenEOF, "", l.pos, l.pos)
+	l.done = true
+}
+
+// more lê a próxima linha do reader para o buffer. Retorna false se não havia
+// mais nada para ler.
+func (l *Lexer) more() bool {
+	if l.reader == nil {
+		return false
+	}
+	line, err := l.reader.ReadString('\n')
+	l.input += line
+	if err != nil {
+		l.reader = nil
+	}
+	return len(line) > 0
+}
+
+// step tokeniza a partir da posição atual, produzindo zero ou mais tokens.
+// Retorna false quando o input acabou ou um erro impede continuar.
+func (l *Lexer) step() bool {
+	if l.failed {
+		return false
+	}
+
+	// Descarta o trecho já tokenizado para não acumular o código inteiro
+	if l.pos >= 4096 {
+		l.base += l.pos
+		l.input = l.input[l.pos:]
+		l.pos = 0
+	}
+	// Garante que a linha atual está inteira no buffer; apenas strings
+	// atravessam linhas, e elas pedem mais input por conta própria
+	for strings.IndexByte(l.input[l.pos:], '\n') < 0 && l.more() {
+	}
+	if l.pos >= len(l.input) {
+		return false
+	}
+
+	// Ler a próxima sequência de bytes como string para comparar emojis
+	remaining := l.input[l.pos:]
+
+	// Verificar palavras-chave e operadores primeiro
+	if typ, n := l.keywords.Match(remaining); n > 0 {
+		// Fora de strings o emoji de interpolação não é tokenizado, a
+		// interpolação é tratada dentro das strings
+		if typ != TokenInterpolate {
+			l.emit(typ, remaining[:n], l.pos, l.pos+n)
 		}
-		if strings.HasPrefix(remaining, "👨🏿‍💻") {
-			l.tokens = append(l.tokens, Token{Type: TokenTry, Value: "👨🏿‍💻"})
-			l.pos += len("👨🏿‍💻")
-			continue
+		l.pos += n
+		return true
+	}
+
+	// Ler o próximo rune (decodificando UTF-8)
+	r, size := utf8.DecodeRuneInString(remaining)
+
+	switch {
+	case r == '{':
+		if depth := len(l.interpolations); depth > 0 {
+			l.interpolations[depth-1].braces++
 		}
-		if strings.HasPrefix(remaining, "🤦🏿‍♂️") {
-			l.tokens = append(l.tokens, Token{Type: TokenCatch, Value: "🤦🏿‍♂️"})
-			l.pos += len("🤦🏿‍♂️")
-			continue
+		l.emit(TokenLBrace, "{", l.pos, l.pos+1)
+		l.pos++
+	case r == '}':
+		l.emit(TokenRBrace, "}", l.pos, l.pos+1)
+		l.pos++
+		if depth := len(l.interpolations); depth > 0 {
+			if l.interpolations[depth-1].braces > 0 {
+				l.interpolations[depth-1].braces--
+				return true
+			}
+			// Fim da interpolação: continua lendo o restante da string
+			quote := l.interpolations[depth-1].quote
+			l.interpolations = l.interpolations[:depth-1]
+			if !l.lexStringBody(quote, l.pos) {
+				return false
+			}
 		}
-		if strings.HasPrefix(remaining, string('🚀')) {
-			l.tokens = append(l.tokens, Token{Type: TokenTryStart, Value: string('🚀')})
-			l.pos += len(string('🚀'))
-			continue
+	case r == '(':
+		l.emit(TokenLParen, "(", l.pos, l.pos+1)
+		l.pos++
+	case r == ')':
+		l.emit(TokenRParen, ")", l.pos, l.pos+1)
+		l.pos++
+	case r == ',':
+		l.emit(TokenComma, ",", l.pos, l.pos+1)
+		l.pos++
+	case r == '"':
+		if strings.HasPrefix(remaining, `"""`) {
+			if !l.lexTripleString(false, l.pos) {
+				return false
+			}
+			return true
 		}
-		if strings.HasPrefix(remaining, "▶️") {
-			l.tokens = append(l.tokens, Token{Type: TokenFunction, Value: "▶️"})
-			l.pos += len("▶️")
-			continue
+		start := l.pos
+		l.pos++ // Pula o "
+		if !l.lexStringBody(`"`, start) {
+			return false
 		}
-		if strings.HasPrefix(remaining, "↩️") {
-			l.tokens = append(l.tokens, Token{Type: TokenReturn, Value: "↩️"})
-			l.pos += len("↩️")
-			continue
+	case r == 'r' && strings.HasPrefix(remaining, `r"`):
+		// String raw: sem escapes nem interpolação
+		if !l.lexRawString() {
+			return false
 		}
-		if strings.HasPrefix(remaining, "✖️") {
-			l.tokens = append(l.tokens, Token{Type: TokenMult, Value: "✖️"})
-			l.pos += len("✖️")
-			continue
+	case isIdentifierStart(r):
+		start := l.pos
+		l.pos += size
+		for l.pos < len(l.input) {
+			next, nextSize := utf8.DecodeRuneInString(l.input[l.pos:])
+			if !isIdentifierPart(next) {
+				break
+			}
+			l.pos += nextSize
 		}
-		if strings.HasPrefix(remaining, "➕") {
-			l.tokens = append(l.tokens, Token{Type: TokenNumPlus, Value: "➕"})
-			l.pos += len("➕")
-			continue
+		value := l.input[start:l.pos]
+
+		// Palavras-chave já foram reconhecidas pela tabela; aqui só
+		// restam os valores booleanos e os identificadores
+		if value == "true" || value == "false" {
+			l.emit(TokenBoolean, value, start, l.pos)
+		} else {
+			l.emit(TokenIdentifier, value, start, l.pos)
 		}
-		if strings.HasPrefix(remaining, "💱") {
-			// Não precisamos tokenizar o emoji de interpolação, a interpolação será tratada no parser
-			l.pos += len("💱")
-			continue
+		return true
+	case r >= '0' && r <= '9':
+		// O literal vai até o fim dos dígitos, letras e _ (0xFF, 1_000);
+		// a validação fica com ParseNumber
+		start := l.pos
+		for l.pos < len(l.input) && isNumberPart(l.input[l.pos]) {
+			l.pos++
 		}
-		// Type emojis
-		if strings.HasPrefix(remaining, "🔢") {
-			l.tokens = append(l.tokens, Token{Type: TokenTypeNumber, Value: "🔢"})
-			l.pos += len("🔢")
-			continue
+		l.emit(TokenNumber, l.input[start:l.pos], start, l.pos)
+		return true
+	case r == '#' && l.base+l.pos == 0 && strings.HasPrefix(remaining, "#!"):
+		// Linha #! no início do arquivo, para scripts executáveis
+		end := strings.IndexByte(remaining, '\n')
+		if end < 0 {
+			end = len(remaining)
 		}
-		if strings.HasPrefix(remaining, "📝") {
-			l.tokens = append(l.tokens, Token{Type: TokenTypeString, Value: "📝"})
-			l.pos += len("📝")
-			continue
+		l.pos += end
+	case r == '/' && strings.HasPrefix(remaining, "//"):
+		// Comentário até o fim da linha
+		end := strings.IndexByte(remaining, '\n')
+		if end < 0 {
+			end = len(remaining)
 		}
-		if strings.HasPrefix(remaining, "⚖️") {
-			l.tokens = append(l.tokens, Token{Type: TokenTypeBool, Value: "⚖️"})
-			l.pos += len("⚖️")
-			continue
+		l.pos += end
+	case unicode.IsSpace(r):
+		l.pos += size // Ignora espaços, tabs e quebras de linha
+	case r == variationSelector || r == 0xFEFF: // Ignora Variation Selector solto e BOM
+		l.pos += size
+	case r == ':':
+		if depth := len(l.interpolations); depth > 0 && l.interpolations[depth-1].braces == 0 {
+			// Especificação de formato da interpolação, até o } final
+			l.pos++
+			start := l.pos
+			for l.pos < len(l.input) && l.input[l.pos] != '}' {
+				l.pos++
+			}
+			l.emit(TokenFormatSpec, l.input[start:l.pos], start, l.pos)
+			return true
 		}
-		if strings.HasPrefix(remaining, "🗑️") {
-			l.tokens = append(l.tokens, Token{Type: TokenTypeAny, Value: "🗑️"})
-			l.pos += len("🗑️")
+		l.emit(TokenTypeColon, ":", l.pos, l.pos+1)
+		l.pos++
+	default:
+		l.errorf(l.base+l.pos, "Caractere inesperado '%s' (Unicode: U+%X)", string(r), r)
+		l.pos += size
+	}
+	return true
+}
+
+// emit adiciona um token que ocupa as posições de pos a end do input
+func (l *Lexer) emit(typ TokenType, value string, pos, end int) {
+	l.tokens = append(l.tokens, Token{Type: typ, Value: value, Pos: l.base + pos, End: l.base + end})
+}
+
+// isNumberPart indica se c pode fazer parte de um literal numérico: dígitos,
+// letras ASCII (prefixos e dígitos hexadecimais) e o separador _.
+func isNumberPart(c byte) bool {
+	return c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c == '_'
+}
+
+// ParseNumber converte o texto de um literal numérico para int. Aceita os
+// prefixos 0x (hexadecimal), 0b (binário) e 0o (octal), o separador _ entre
+// dígitos (1_000_000) e um sinal - opcional.
+func ParseNumber(text string) (int, error) {
+	sign, digits := "", text
+	if strings.HasPrefix(digits, "-") {
+		sign, digits = "-", digits[1:]
+	}
+
+	base := 10
+	if len(digits) > 1 && digits[0] == '0' {
+		switch digits[1] {
+		case 'x', 'X':
+			base = 16
+		case 'b', 'B':
+			base = 2
+		case 'o', 'O':
+			base = 8
+		}
+		if base != 10 {
+			digits = digits[2:]
+		}
+	}
+
+	// _ só é aceito entre dois dígitos
+	if digits == "" || strings.HasPrefix(digits, "_") || strings.HasSuffix(digits, "_") || strings.Contains(digits, "__") {
+		return 0, &strconv.NumError{Func: "ParseNumber", Num: text, Err: strconv.ErrSyntax}
+	}
+	value, err := strconv.ParseInt(sign+strings.ReplaceAll(digits, "_", ""), base, 0)
+	if err != nil {
+		return 0, &strconv.NumError{Func: "ParseNumber", Num: text, Err: err.(*strconv.NumError).Err}
+	}
+	return int(value), nil
+}
+
+// isIdentifierStart indica se r pode iniciar um identificador: qualquer letra
+// Unicode (ação, preço, 名前) ou _.
+func isIdentifierStart(r rune) bool {
+	return unicode.IsLetter(r) || r == '_'
+}
+
+// isIdentifierPart indica se r pode continuar um identificador: letras,
+// dígitos, _ e marcas combinantes (para letras acentuadas em forma
+// decomposta, como c + U+0327).
+func isIdentifierPart(r rune) bool {
+	return isIdentifierStart(r) || unicode.IsDigit(r) || unicode.Is(unicode.M, r)
+}
+
+const (
+	variationSelector = 0xFE0F // VS16: pede a apresentação em emoji
+	zeroWidthJoiner   = 0x200D // ZWJ: junta emojis em uma sequência
+)
+
+// isEmojiModifier indica se r apenas altera a aparência do emoji anterior:
+// o variation selector (🖨 vs 🖨️) e os modificadores de tom de pele
+// (U+1F3FB a U+1F3FF).
+func isEmojiModifier(r rune) bool {
+	return r == variationSelector || (r >= 0x1F3FB && r <= 0x1F3FF)
+}
+
+// skipEmojiModifiers avança i sobre os modificadores de emoji em input
+func skipEmojiModifiers(input string, i int) int {
+	for i < len(input) {
+		r, size := utf8.DecodeRuneInString(input[i:])
+		if !isEmojiModifier(r) {
+			break
+		}
+		i += size
+	}
+	return i
+}
+
+// lexStringBody lê o conteúdo de uma string a partir da posição atual (logo
+// após a " de abertura ou o } que fecha uma interpolação) até encontrar quote;
+// com quote vazio, lê até o fim do input (corpo de strings com três aspas).
+// Cada trecho de texto vira um TokenString; ao encontrar 💱{ emite
+// TokenInterpolate e TokenLBrace e devolve o controle ao run para tokenizar a
+// expressão interpolada. start é onde o trecho começa no código, incluindo a
+// " de abertura. Retorna false se a string não foi terminada.
+func (l *Lexer) lexStringBody(quote string, start int) bool {
+	var content strings.Builder
+	for l.pos < len(l.input) || l.more() {
+		remaining := l.input[l.pos:]
+		if quote != "" && strings.HasPrefix(remaining, quote) {
+			l.emit(TokenString, content.String(), start, l.pos+len(quote))
+			l.pos += len(quote)
+			return true
+		}
+		if strings.HasPrefix(remaining, "💱{") {
+			l.emit(TokenString, content.String(), start, l.pos)
+			l.emit(TokenInterpolate, "💱", l.pos, l.pos+len("💱"))
+			l.emit(TokenLBrace, "{", l.pos+len("💱"), l.pos+len("💱{"))
+			l.pos += len("💱{")
+			l.interpolations = append(l.interpolations, interpolation{quote: quote})
+			return true
+		}
+		if remaining[0] == '\\' {
+			l.lexEscape(&content)
 			continue
 		}
+		content.WriteByte(remaining[0])
+		l.pos++
+	}
 
-		// Ler o próximo rune
-		r := rune(l.input[l.pos])
+	if quote == "" {
+		l.emit(TokenString, content.String(), start, l.pos)
+		return true
+	}
+	l.errorf(l.base+l.pos, "String não terminada")
+	l.failed = true
+	return false
+}
 
-		switch {
-		case r == '{':
-			l.tokens = append(l.tokens, Token{Type: TokenLBrace, Value: "{"})
-			l.pos++
-		case r == '}':
-			l.tokens = append(l.tokens, Token{Type: TokenRBrace, Value: "}"})
-			l.pos++
-		case r == '(':
-			l.tokens = append(l.tokens, Token{Type: TokenLParen, Value: "("})
-			l.pos++
-		case r == ')':
-			l.tokens = append(l.tokens, Token{Type: TokenRParen, Value: ")"})
-			l.pos++
-		case r == ',':
-			l.tokens = append(l.tokens, Token{Type: TokenComma, Value: ","})
-			l.pos++
-		case r == '=':
-			l.tokens = append(l.tokens, Token{Type: TokenEqualSign, Value: "="})
-			l.pos++
-		case r == '+':
-			l.tokens = append(l.tokens, Token{Type: TokenPlus, Value: "+"})
-			l.pos++
-		case r == '*':
-			l.tokens = append(l.tokens, Token{Type: TokenMult, Value: "*"})
-			l.pos++
-		case r == '"':
-			l.pos++
-			start := l.pos
-			stringContent := ""
-
-			for l.pos < len(l.input) && l.input[l.pos] != '"' {
-				// Verificar se temos uma interpolação dentro da string
-				if l.pos+2 < len(l.input) &&
-					strings.HasPrefix(l.input[l.pos:], "💱{") {
-					// Adiciona o conteúdo até aqui como uma string
-					if l.pos > start {
-						stringContent += l.input[start:l.pos]
-					}
-
-					// Adiciona o token de interpolação
-					l.tokens = append(l.tokens, Token{Type: TokenString, Value: stringContent})
-					stringContent = "" // Reseta o conteúdo
-
-					// Avança além do 💱{
-					l.pos += len("💱{")
-					l.tokens = append(l.tokens, Token{Type: TokenInterpolate, Value: "💱"})
-					l.tokens = append(l.tokens, Token{Type: TokenLBrace, Value: "{"})
-
-					// Captura o identificador dentro das chaves
-					identStart := l.pos
-					for l.pos < len(l.input) && l.input[l.pos] != '}' {
-						l.pos++
-					}
-
-					if l.pos >= len(l.input) {
-						fmt.Printf("Interpolação não terminada na posição %d\n", l.pos)
-						return l.tokens
-					}
-
-					// Adiciona o identificador
-					identifier := l.input[identStart:l.pos]
-					l.tokens = append(l.tokens, Token{Type: TokenIdentifier, Value: identifier})
-					l.tokens = append(l.tokens, Token{Type: TokenRBrace, Value: "}"})
-
-					l.pos++ // Pula o }
-					start = l.pos
-					continue
-				}
+// escapes associa cada sequência de escape simples ao texto que ela produz.
+var escapes = map[byte]string{
+	'n':  "\n",
+	't':  "\t",
+	'r':  "\r",
+	'0':  "\x00",
+	'"':  "\"",
+	'\\': "\\",
+}
 
-				l.pos++
-			}
+// lexEscape interpreta uma sequência de escape iniciada por \ na posição
+// atual: \n, \t, \r, \0, \", \\, \u{1F600} e \💱 (um 💱 literal, que
+// permite escrever \💱{ sem abrir uma interpolação).
+func (l *Lexer) lexEscape(content *strings.Builder) {
+	start := l.pos
+	l.pos++ // Pula a \
+	if l.pos >= len(l.input) {
+		content.WriteByte('\\')
+		return
+	}
 
-			// Adiciona qualquer texto restante como uma string
-			if l.pos > start {
-				stringContent += l.input[start:l.pos]
-			}
+	if text, ok := escapes[l.input[l.pos]]; ok {
+		content.WriteString(text)
+		l.pos++
+		return
+	}
 
-			if l.pos >= len(l.input) {
-				fmt.Printf("String não terminada na posição %d\n", l.pos)
-				return l.tokens
-			}
+	remaining := l.input[l.pos:]
+	if strings.HasPrefix(remaining, "💱") {
+		content.WriteString("💱")
+		l.pos += len("💱")
+		return
+	}
 
-			l.tokens = append(l.tokens, Token{Type: TokenString, Value: stringContent})
-			l.pos++ // Pula o "
-		case unicode.IsLetter(r):
-			start := l.pos
-			for l.pos < len(l.input) && (unicode.IsLetter(rune(l.input[l.pos])) || unicode.IsDigit(rune(l.input[l.pos]))) {
-				l.pos++
-			}
-			value := l.input[start:l.pos]
-
-			// Verificar palavras-chave e valores booleanos
-			if value == "true" || value == "false" {
-				l.tokens = append(l.tokens, Token{Type: TokenBoolean, Value: value})
-			} else if value == "main" {
-				l.tokens = append(l.tokens, Token{Type: TokenMain, Value: value})
-			} else {
-				l.tokens = append(l.tokens, Token{Type: TokenIdentifier, Value: value})
+	if strings.HasPrefix(remaining, "u{") {
+		end := strings.IndexByte(remaining, '}')
+		if end > 2 {
+			code, err := strconv.ParseUint(remaining[2:end], 16, 32)
+			if err == nil && utf8.ValidRune(rune(code)) {
+				content.WriteRune(rune(code))
+				l.pos += end + 1
+				return
 			}
+		}
+		l.errorf(l.base+start, "Escape unicode inválido")
+		content.WriteString("\\")
+		return
+	}
+
+	l.errorf(l.base+start, "Sequência de escape inválida '\\%c'", l.input[l.pos])
+	content.WriteByte('\\')
+}
+
+// lexRawString lê uma string raw (r"..." ou r"""..."""), em que \ e 💱{ não
+// têm significado especial.
+func (l *Lexer) lexRawString() bool {
+	start := l.pos
+	l.pos++ // Pula o r
+	if strings.HasPrefix(l.input[l.pos:], `"""`) {
+		return l.lexTripleString(true, start)
+	}
+
+	l.pos++ // Pula o "
+	end := strings.IndexByte(l.input[l.pos:], '"')
+	for end < 0 && l.more() {
+		end = strings.IndexByte(l.input[l.pos:], '"')
+	}
+	if end < 0 {
+		l.errorf(l.base+len(l.input), "String não terminada")
+		l.failed = true
+		return false
+	}
+	l.emit(TokenString, l.input[l.pos:l.pos+end], start, l.pos+end+1)
+	l.pos += end + 1
+	return true
+}
+
+// lexTripleString lê uma string multilinha delimitada por três aspas. A
+// indentação comum das linhas é removida, assim como a quebra de linha logo
+// após as aspas de abertura e a linha em branco antes das aspas de
+// fechamento. Strings raw não processam escapes nem interpolações.
+func (l *Lexer) lexTripleString(raw bool, quoteStart int) bool {
+	l.pos += len(`"""`)
+	start := l.pos
+	for !strings.HasPrefix(l.input[l.pos:], `"""`) {
+		if l.pos+len(`"""`) > len(l.input) && l.more() {
 			continue
-		case unicode.IsDigit(r):
-			start := l.pos
-			for l.pos < len(l.input) && unicode.IsDigit(rune(l.input[l.pos])) {
-				l.pos++
-			}
-			l.tokens = append(l.tokens, Token{Type: TokenNumber, Value: l.input[start:l.pos]})
+		}
+		if l.pos >= len(l.input) {
+			l.errorf(l.base+len(l.input), "String não terminada")
+			l.failed = true
+			return false
+		}
+		if !raw && l.input[l.pos] == '\\' {
+			l.pos++ // Um \" escapado não fecha a string
+		}
+		l.pos++
+	}
+
+	body := dedent(l.input[start:l.pos])
+	l.pos += len(`"""`)
+
+	if raw {
+		l.emit(TokenString, body, quoteStart, l.pos)
+		return true
+	}
+
+	// O corpo já sem indentação é tokenizado por um lexer próprio, que trata
+	// escapes e interpolações até o fim do texto. As posições dos tokens do
+	// corpo são aproximadas, pois a indentação removida desloca o texto
+	sub := NewLexerWithKeywords(body, l.keywords)
+	sub.base = l.base + start
+	sub.errors = l.errors
+	if sub.lexStringBody("", 0) {
+		for sub.step() {
+		}
+	}
+	if sub.failed {
+		l.failed = true
+		return false
+	}
+	if len(sub.interpolations) > 0 {
+		l.errorf(l.base+l.pos, "Interpolação não terminada")
+	}
+	// A string como um todo vai das aspas de abertura às de fechamento
+	sub.tokens[0].Pos = l.base + quoteStart
+	sub.tokens[len(sub.tokens)-1].End = l.base + l.pos
+	l.tokens = append(l.tokens, sub.tokens...)
+	return true
+}
+
+// dedent remove a indentação comum das linhas de uma string multilinha
+func dedent(body string) string {
+	lines := strings.Split(strings.ReplaceAll(body, "\r\n", "\n"), "\n")
+	if len(lines) > 1 && strings.TrimSpace(lines[0]) == "" {
+		lines = lines[1:]
+	}
+	if len(lines) > 1 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	indent := -1
+	for _, line := range lines {
+		if strings.TrimSpace(line) == "" {
 			continue
-		case unicode.IsSpace(r) || r == '\n' || r == '\r' || r == '\t':
-			l.pos++ // Ignora espaços, tabs e quebras de linha
-		case r == 0xFE0F || r == 0xFEFF: // Ignora Variation Selector e BOM
-			l.pos++
-		case r == '.':
-			l.tokens = append(l.tokens, Token{Type: TokenConcat, Value: "."})
-			l.pos++
-		case r == ':':
-			l.tokens = append(l.tokens, Token{Type: TokenTypeColon, Value: ":"})
-			l.pos++
-		default:
-			fmt.Printf("Caractere inesperado na posição %d: '%s' (Unicode: U+%X)\n", l.pos, string(r), r)
-			l.pos++
+		}
+		width := len(line) - len(strings.TrimLeft(line, " \t"))
+		if indent < 0 || width < indent {
+			indent = width
+		}
+	}
+
+	for i, line := range lines {
+		if len(line) >= indent && indent > 0 {
+			lines[i] = line[indent:]
+		} else if strings.TrimSpace(line) == "" {
+			lines[i] = ""
 		}
 	}
-	l.tokens = append(l.tokens, Token{Type: TokenEOF, Value: ""})
-	fmt.Println("Tokens gerados:", l.tokens) // Log para depuração
-	return l.tokens
+	return strings.Join(lines, "\n")
 }
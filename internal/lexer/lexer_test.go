@@ -0,0 +1,326 @@
+package lexer
+
+import (
+	"errors"
+	"io"
+	"math"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestParseNumber(t *testing.T) {
+	tests := []struct {
+		text string
+		want int
+		err  error // strconv.ErrSyntax ou strconv.ErrRange; nil se não há erro
+	}{
+		{"0", 0, nil},
+		{"42", 42, nil},
+		{"-42", -42, nil},
+		{"007", 7, nil},
+		{"1_000_000", 1000000, nil},
+		{"0xFF", 255, nil},
+		{"0XfF", 255, nil},
+		{"0x_ff", 0, strconv.ErrSyntax},
+		{"0b1010", 10, nil},
+		{"0B1_0", 2, nil},
+		{"0o755", 493, nil},
+		{"-0x10", -16, nil},
+		{"9223372036854775807", math.MaxInt64, nil},
+		{"-9223372036854775808", math.MinInt64, nil},
+		{"9223372036854775808", 0, strconv.ErrRange},
+		{"0x8000000000000000", 0, strconv.ErrRange},
+		{"", 0, strconv.ErrSyntax},
+		{"-", 0, strconv.ErrSyntax},
+		{"0x", 0, strconv.ErrSyntax},
+		{"_1", 0, strconv.ErrSyntax},
+		{"1_", 0, strconv.ErrSyntax},
+		{"1__0", 0, strconv.ErrSyntax},
+		{"0b102", 0, strconv.ErrSyntax},
+		{"0o8", 0, strconv.ErrSyntax},
+		{"12abc", 0, strconv.ErrSyntax},
+		{"--1", 0, strconv.ErrSyntax},
+	}
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			got, err := ParseNumber(tt.text)
+			if tt.err != nil {
+				if !errors.Is(err, tt.err) {
+					t.Fatalf("ParseNumber(%q) = %d, %v; esperado erro %v", tt.text, got, err, tt.err)
+				}
+				var numErr *strconv.NumError
+				if !errors.As(err, &numErr) || numErr.Num != tt.text {
+					t.Errorf("ParseNumber(%q): o erro %v deveria citar o texto original", tt.text, err)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("ParseNumber(%q) = %d, %v; esperado %d", tt.text, got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestLexNumber(t *testing.T) {
+	tests := []struct {
+		input string
+		want  []Token // Tokens esperados, sem o EOF
+	}{
+		{"1_000", []Token{{Type: TokenNumber, Value: "1_000", Pos: 0, End: 5}}},
+		{"0xFF", []Token{{Type: TokenNumber, Value: "0xFF", Pos: 0, End: 4}}},
+		// Letras coladas ao número fazem parte do literal, e ParseNumber o
+		// rejeita depois, em vez de virar um número seguido de identificador
+		{"12abc", []Token{{Type: TokenNumber, Value: "12abc", Pos: 0, End: 5}}},
+		// O sinal é um operador à parte
+		{"-5", []Token{
+			{Type: TokenMinus, Value: "-", Pos: 0, End: 1},
+			{Type: TokenNumber, Value: "5", Pos: 1, End: 2},
+		}},
+		{"2✖️0b11", []Token{
+			{Type: TokenNumber, Value: "2", Pos: 0, End: 1},
+			{Type: TokenMult, Value: "✖️", Pos: 1, End: 7},
+			{Type: TokenNumber, Value: "0b11", Pos: 7, End: 11},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			tokens := NewLexer(tt.input).Lex()
+			if len(tokens) == 0 || tokens[len(tokens)-1].Type != TokenEOF {
+				t.Fatalf("Lex(%q) = %v, esperado terminar em EOF", tt.input, tokens)
+			}
+			tokens = tokens[:len(tokens)-1]
+			if len(tokens) != len(tt.want) {
+				t.Fatalf("Lex(%q) = %v, esperado %v", tt.input, tokens, tt.want)
+			}
+			for i := range tokens {
+				if tokens[i] != tt.want[i] {
+					t.Errorf("Lex(%q)[%d] = %+v, esperado %+v", tt.input, i, tokens[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestErrorOutput(t *testing.T) {
+	if l := NewLexer(""); l.errors != os.Stderr {
+		t.Errorf("saída de erros padrão = %v, esperado os.Stderr", l.errors)
+	}
+
+	var out strings.Builder
+	l := NewLexer("🖨️ 1 $ 2 @")
+	l.SetErrorOutput(&out)
+	l.Lex()
+	errs := l.Errors()
+	if len(errs) != 2 || errs[0].Pos != 10 || errs[1].Pos != 14 {
+		t.Fatalf("Errors() = %v, esperado erros nas posições 10 e 14", errs)
+	}
+	if want := errs[0].Error() + "\n" + errs[1].Error() + "\n"; out.String() != want {
+		t.Errorf("saída de erros = %q, esperado %q", out.String(), want)
+	}
+}
+
+func TestLexString(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  []Token // Tipo e valor dos tokens esperados, sem o EOF
+		err   bool    // Se deve haver um erro léxico
+	}{
+		{"simples", `"olá"`, []Token{{Type: TokenString, Value: "olá"}}, false},
+		{"escapes", `"a\tb\n\"c\"\\"`, []Token{{Type: TokenString, Value: "a\tb\n\"c\"\\"}}, false},
+		{"escape unicode", `"\u{1F600}\u{e9}"`, []Token{{Type: TokenString, Value: "😀é"}}, false},
+		{"💱 escapado", `"\💱{x}"`, []Token{{Type: TokenString, Value: "💱{x}"}}, false},
+		{"escape inválido", `"a\qb"`, []Token{{Type: TokenString, Value: `a\qb`}}, true},
+		{"escape unicode inválido", `"\u{110000}"`, []Token{{Type: TokenString, Value: `\u{110000}`}}, true},
+		{"interpolação", `"a💱{x}b"`, []Token{
+			{Type: TokenString, Value: "a"},
+			{Type: TokenInterpolate, Value: "💱"},
+			{Type: TokenLBrace, Value: "{"},
+			{Type: TokenIdentifier, Value: "x"},
+			{Type: TokenRBrace, Value: "}"},
+			{Type: TokenString, Value: "b"},
+		}, false},
+		{"raw", `r"a\n💱{x}"`, []Token{{Type: TokenString, Value: `a\n💱{x}`}}, false},
+		{"três aspas", "\"\"\"\n    um\n      dois\n    \"\"\"", []Token{{Type: TokenString, Value: "um\n  dois"}}, false},
+		{"três aspas com escape", "\"\"\"a\\\"\"\"b\"\"\"", []Token{{Type: TokenString, Value: `a"""b`}}, false},
+		{"três aspas raw", "r\"\"\"\n  a\\n\n  b\n\"\"\"", []Token{{Type: TokenString, Value: "a\\n\nb"}}, false},
+		{"não terminada", `"abc`, nil, true},
+		{"raw não terminada", `r"abc`, nil, true},
+		{"três aspas não terminada", `"""abc"`, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := NewLexer(tt.input)
+			l.SetErrorOutput(io.Discard)
+			tokens := l.Lex()
+			if errs := l.Errors(); (len(errs) > 0) != tt.err {
+				t.Errorf("Lex(%q): erros %v, esperado erro: %v", tt.input, errs, tt.err)
+			}
+			sameTokens(t, tt.input, tokens, tt.want)
+		})
+	}
+}
+
+func TestLexUnicode(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  []Token // Tipo e valor dos tokens esperados, sem o EOF
+	}{
+		{"identificadores acentuados", "✍️ ação = preço", []Token{
+			{Type: TokenAssign, Value: "✍️"},
+			{Type: TokenIdentifier, Value: "ação"},
+			{Type: TokenEqualSign, Value: "="},
+			{Type: TokenIdentifier, Value: "preço"},
+		}},
+		{"outros alfabetos", "名前 _x1", []Token{
+			{Type: TokenIdentifier, Value: "名前"},
+			{Type: TokenIdentifier, Value: "_x1"},
+		}},
+		{"acento decomposto", "ac\u0327a\u0303o", []Token{{Type: TokenIdentifier, Value: "ac\u0327a\u0303o"}}},
+		{"sem variation selector", "🖨 1", []Token{
+			{Type: TokenPrint, Value: "🖨"},
+			{Type: TokenNumber, Value: "1"},
+		}},
+		{"com variation selector", "🖨️1", []Token{
+			{Type: TokenPrint, Value: "🖨️"},
+			{Type: TokenNumber, Value: "1"},
+		}},
+		{"outro tom de pele", "👨🏻‍💻 🤦‍♂️", []Token{
+			{Type: TokenTry, Value: "👨🏻‍💻"},
+			{Type: TokenCatch, Value: "🤦‍♂️"},
+		}},
+		{"palavra-chave dentro de identificador", "mainframe", []Token{{Type: TokenIdentifier, Value: "mainframe"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := NewLexer(tt.input)
+			l.SetErrorOutput(io.Discard)
+			tokens := l.Lex()
+			if errs := l.Errors(); len(errs) > 0 {
+				t.Errorf("Lex(%q): erros inesperados %v", tt.input, errs)
+			}
+			sameTokens(t, tt.input, tokens, tt.want)
+		})
+	}
+}
+
+// Outra sequência ZWJ não é a palavra-chave, mesmo começando igual a ela
+func TestLexOtherZWJSequence(t *testing.T) {
+	for _, input := range []string{"🤦‍♀️", "👨‍💻‍🔧"} {
+		l := NewLexer(input)
+		l.SetErrorOutput(io.Discard)
+		for _, token := range l.Lex() {
+			if token.Type == TokenTry || token.Type == TokenCatch {
+				t.Errorf("Lex(%q) reconheceu %s", input, token.Type)
+			}
+		}
+		if len(l.Errors()) == 0 {
+			t.Errorf("Lex(%q): esperado erro de caractere inesperado", input)
+		}
+	}
+}
+
+// sameTokens compara o tipo e o valor dos tokens, sem o EOF, com want
+func sameTokens(t *testing.T, input string, tokens, want []Token) {
+	t.Helper()
+	tokens = tokens[:len(tokens)-1]
+	if len(tokens) != len(want) {
+		t.Fatalf("Lex(%q) = %v, esperado %v", input, tokens, want)
+	}
+	for i := range tokens {
+		if tokens[i].Type != want[i].Type || tokens[i].Value != want[i].Value {
+			t.Errorf("Lex(%q)[%d] = %s %q, esperado %s %q", input, i,
+				tokens[i].Type, tokens[i].Value, want[i].Type, want[i].Value)
+		}
+	}
+}
+
+// O lexer sobre um io.Reader produz os mesmos tokens, com as mesmas posições,
+// que o lexer sobre a string, inclusive depois de descartar o início do buffer
+func TestReaderLexer(t *testing.T) {
+	var code strings.Builder
+	for i := 0; i < 500; i++ {
+		code.WriteString("✍️ ação = \"\"\"\n  linha 💱{i}\n\"\"\" // comentário\n🖨️ r\"a\nb\", 0xFF\n")
+	}
+	source := code.String()
+
+	want := NewLexer(source).Lex()
+	got := slices.Collect(NewReaderLexer(strings.NewReader(source)).Tokens())
+	if len(got) != len(want) {
+		t.Fatalf("%d tokens, esperado %d", len(got), len(want))
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("token %d = %+v, esperado %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestPeek(t *testing.T) {
+	l := NewLexer("✍️ x = 1")
+	if l.Peek(2).Type != TokenEqualSign || l.Peek(0).Type != TokenAssign {
+		t.Fatalf("Peek(2), Peek(0) = %v, %v", l.Peek(2), l.Peek(0))
+	}
+	for _, want := range []TokenType{TokenAssign, TokenIdentifier, TokenEqualSign, TokenNumber, TokenEOF, TokenEOF} {
+		if token := l.NextToken(); token.Type != want {
+			t.Errorf("NextToken() = %v, esperado %s", token, want)
+		}
+	}
+	if token := l.Peek(10); token.Type != TokenEOF || token.Pos != len("✍️ x = 1") {
+		t.Errorf("Peek depois do fim = %+v, esperado EOF no fim do código", token)
+	}
+}
+
+// O lexer lê do reader só o necessário para produzir os tokens pedidos
+func TestReaderLexerIsLazy(t *testing.T) {
+	r := &countingReader{r: strings.NewReader(strings.Repeat("🖨️ 1\n", 10000))}
+	l := NewReaderLexer(r)
+	l.NextToken()
+	if r.n >= 10000*len("🖨️ 1\n") {
+		t.Errorf("lidos %d bytes para o primeiro token", r.n)
+	}
+	for token := range l.Tokens() {
+		if token.Type == TokenNumber {
+			break // Parar o iterador não consome o resto
+		}
+	}
+	if token := l.NextToken(); token.Type != TokenPrint {
+		t.Errorf("NextToken() depois do break = %v, esperado PRINT", token)
+	}
+}
+
+func TestIncomplete(t *testing.T) {
+	tests := []struct {
+		input string
+		want  bool
+	}{
+		{`🖨️ "abc"`, false},
+		{`🖨️ "abc`, true},
+		{`🖨️ "a💱{x`, true},
+		{"🖨️ \"\"\"a\n", true},
+		{`🖨️ (1`, false}, // Erro do parser, não do lexer
+	}
+	for _, tt := range tests {
+		l := NewLexer(tt.input)
+		l.SetErrorOutput(io.Discard)
+		l.Lex()
+		if got := l.Incomplete(); got != tt.want {
+			t.Errorf("Incomplete(%q) = %v, esperado %v", tt.input, got, tt.want)
+		}
+	}
+}
+
+type countingReader struct {
+	r io.Reader
+	n int
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += n
+	return n, err
+}
@@ -0,0 +1,35 @@
+package lexer
+
+import (
+	"slices"
+	"unicode/utf8"
+)
+
+// LineIndex converte posições em bytes do código fonte (Token.Pos,
+// Token.End) em linha e coluna.
+type LineIndex struct {
+	source string
+	starts []int // Posição do início de cada linha
+}
+
+// NewLineIndex indexa as linhas de source.
+func NewLineIndex(source string) *LineIndex {
+	starts := []int{0}
+	for i := 0; i < len(source); i++ {
+		if source[i] == '\n' {
+			starts = append(starts, i+1)
+		}
+	}
+	return &LineIndex{source: source, starts: starts}
+}
+
+// Position retorna a linha e a coluna de pos, contadas a partir de 1. A
+// coluna conta caracteres, não bytes.
+func (x *LineIndex) Position(pos int) (line, column int) {
+	pos = max(0, min(pos, len(x.source)))
+	line, found := slices.BinarySearch(x.starts, pos)
+	if !found {
+		line--
+	}
+	return line + 1, utf8.RuneCountInString(x.source[x.starts[line]:pos]) + 1
+}
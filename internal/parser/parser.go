@@ -1,415 +1,109 @@
 package parser
 
 import (
+	"errors"
 	"fmt"
+	"melhorzin-lang/internal/ast"
 	"melhorzin-lang/internal/lexer"
-	"regexp"
 	"strconv"
-	"strings"
 )
 
-// Type representa um tipo de dados na linguagem.
-type Type string
-
-const (
-	TypeNumber Type = "NUMBER"
-	TypeString Type = "STRING"
-	TypeBool   Type = "BOOL"
-	TypeAny    Type = "ANY"
-)
-
-// Node representa um nó da AST.
-type Node interface {
-	Evaluate(vars map[string]interface{}) interface{}
-	// Novo método para verificação de tipos
-	GetType() Type
-}
-
-// PrintNode para instruções de impressão.
-type PrintNode struct {
-	Text         string
-	Interpolated bool
-	Variables    map[string]struct{} // Variáveis que serão interpoladas
-}
-
-func (n *PrintNode) Evaluate(vars map[string]interface{}) interface{} {
-	if !n.Interpolated {
-		// Garantir que o texto seja impresso
-		fmt.Println(n.Text)
-		return n.Text
-	}
-
-	// Processa a interpolação
-	result := n.Text
-	for varName := range n.Variables {
-		if val, exists := vars[varName]; exists {
-			placeholder := fmt.Sprintf("💱{%s}", varName)
-			result = strings.Replace(result, placeholder, fmt.Sprintf("%v", val), -1)
-		}
-	}
-
-	// Garantir que o resultado seja impresso
-	fmt.Println(result)
-	return result
-}
-
-func (n *PrintNode) GetType() Type {
-	return TypeString
-}
-
-// AssignNode para atribuições.
-type AssignNode struct {
-	Name         string
-	Value        interface{}
-	DeclaredType Type // Tipo declarado explicitamente
-	InferredType Type // Tipo inferido do valor
-}
-
-func (n *AssignNode) Evaluate(vars map[string]interface{}) interface{} {
-	// Se o valor for um nó, avaliá-lo primeiro
-	var value interface{}
-
-	if node, ok := n.Value.(Node); ok {
-		value = node.Evaluate(vars)
-
-		// Verificação de tipo dinâmica
-		valueType := node.GetType()
-		if n.DeclaredType != TypeAny && n.DeclaredType != valueType {
-			panic(fmt.Sprintf("Erro de tipo: esperado %s para variável %s, mas recebeu %s",
-				n.DeclaredType, n.Name, valueType))
-		}
-	} else {
-		value = n.Value
-
-		// Verificação de tipo para valores literais
-		if n.DeclaredType != TypeAny {
-			switch n.DeclaredType {
-			case TypeNumber:
-				if _, ok := value.(int); !ok {
-					panic(fmt.Sprintf("Erro de tipo: esperado NUMBER para variável %s", n.Name))
-				}
-			case TypeString:
-				if _, ok := value.(string); !ok {
-					panic(fmt.Sprintf("Erro de tipo: esperado STRING para variável %s", n.Name))
-				}
-			case TypeBool:
-				if _, ok := value.(bool); !ok {
-					panic(fmt.Sprintf("Erro de tipo: esperado BOOL para variável %s", n.Name))
-				}
-			}
-		}
-	}
-
-	vars[n.Name] = value
-	return nil
-}
-
-func (n *AssignNode) GetType() Type {
-	if n.DeclaredType != TypeAny {
-		return n.DeclaredType
-	}
-
-	// Inferir tipo
-	if node, ok := n.Value.(Node); ok {
-		return node.GetType()
-	}
-
-	switch n.Value.(type) {
-	case int:
-		return TypeNumber
-	case string:
-		return TypeString
-	case bool:
-		return TypeBool
-	default:
-		return TypeAny
-	}
-}
-
-// EqualNode para comparações.
-type EqualNode struct {
-	Name  string
-	Value interface{}
-}
-
-func (n *EqualNode) Evaluate(vars map[string]interface{}) interface{} {
-	if val, exists := vars[n.Name]; exists {
-		return val == n.Value
-	}
-	return false
+// Parser contém o estado do parser.
+type Parser struct {
+	tokens TokenStream
+	vars   map[string]ast.Type // Armazenar tipos de variáveis
+	end    int                 // Fim do último token consumido
 }
 
-func (n *EqualNode) GetType() Type {
-	return TypeBool
+// TokenStream é a origem dos tokens do parser, consumidos sob demanda.
+// *lexer.Lexer implementa essa interface.
+type TokenStream interface {
+	// NextToken consome e retorna o próximo token
+	NextToken() lexer.Token
+	// Peek retorna o token n posições à frente sem consumi-lo
+	Peek(n int) lexer.Token
 }
 
-// BinaryOpNode para operações binárias
-type BinaryOpNode struct {
-	Left  Node
-	Op    lexer.TokenType
-	Right Node
+// NewParser cria um novo parser que lê tokens de tokens à medida que precisa.
+func NewParser(tokens TokenStream) *Parser {
+	return NewParserWithTypes(tokens, make(map[string]ast.Type))
 }
 
-func (n *BinaryOpNode) Evaluate(vars map[string]interface{}) interface{} {
-	leftVal := n.Left.Evaluate(vars)
-	rightVal := n.Right.Evaluate(vars)
-
-	// Verificação de tipo durante a avaliação
-	leftType := n.Left.GetType()
-	rightType := n.Right.GetType()
-
-	switch n.Op {
-	case lexer.TokenPlus:
-		// + agora é só para soma numérica
-		if leftType != TypeNumber || rightType != TypeNumber {
-			panic(fmt.Sprintf("Erro de tipo: Operação + requer operandos do tipo NUMBER"))
-		}
-
-		if leftInt, ok := leftVal.(int); ok {
-			if rightInt, ok := rightVal.(int); ok {
-				return leftInt + rightInt
-			}
-		}
-		return nil
-	case lexer.TokenConcat:
-		// . é para concatenação de strings
-		return fmt.Sprintf("%v%v", leftVal, rightVal)
-	case lexer.TokenNumPlus:
-		// ➕ é para soma numérica (manter por compatibilidade)
-		if leftType != TypeNumber || rightType != TypeNumber {
-			panic(fmt.Sprintf("Erro de tipo: Operação ➕ requer operandos do tipo NUMBER"))
-		}
-
-		if leftInt, ok := leftVal.(int); ok {
-			if rightInt, ok := rightVal.(int); ok {
-				return leftInt + rightInt
-			}
-		}
-		return nil
-	case lexer.TokenMult:
-		// ✖️ é para multiplicação numérica
-		if leftType != TypeNumber || rightType != TypeNumber {
-			panic(fmt.Sprintf("Erro de tipo: Operação * requer operandos do tipo NUMBER"))
-		}
-
-		if leftInt, ok := leftVal.(int); ok {
-			if rightInt, ok := rightVal.(int); ok {
-				return leftInt * rightInt
-			}
-		}
+// NewParserWithTypes cria um parser que parte dos tipos de variáveis já
+// conhecidos em types e registra neles as novas declarações. Permite analisar
+// um programa em partes (REPL, embedding) mantendo a verificação de tipos.
+func NewParserWithTypes(tokens TokenStream, types map[string]ast.Type) *Parser {
+	return &Parser{
+		tokens: tokens,
+		vars:   types,
 	}
-	return nil
 }
 
-func (n *BinaryOpNode) GetType() Type {
-	if n.Op == lexer.TokenConcat {
-		return TypeString
+// Parse analisa todos os tokens e retorna a AST.
+func (p *Parser) Parse() []ast.Node {
+	var nodes []ast.Node
+	for node := p.Next(); node != nil; node = p.Next() {
+		nodes = append(nodes, node)
 	}
-	return TypeNumber
-}
-
-// VariableNode para acessar variáveis
-type VariableNode struct {
-	Name string
-	Type Type // Tipo da variável
-}
-
-func (n *VariableNode) Evaluate(vars map[string]interface{}) interface{} {
-	if val, exists := vars[n.Name]; exists {
-		return val
-	}
-	return nil
-}
-
-func (n *VariableNode) GetType() Type {
-	return n.Type
-}
-
-// FunctionNode para definição de funções
-type FunctionNode struct {
-	Name       string
-	Parameters []string
-	ParamTypes []Type // Tipos dos parâmetros
-	ReturnType Type   // Tipo de retorno
-	Body       []Node
-}
-
-func (n *FunctionNode) Evaluate(vars map[string]interface{}) interface{} {
-	// Armazena a função no mapa de variáveis
-	vars[n.Name] = n
-	return nil
-}
-
-func (n *FunctionNode) GetType() Type {
-	return n.ReturnType
-}
-
-// ReturnNode para retorno de valores
-type ReturnNode struct {
-	Value Node
-}
-
-func (n *ReturnNode) Evaluate(vars map[string]interface{}) interface{} {
-	return n.Value.Evaluate(vars)
-}
-
-func (n *ReturnNode) GetType() Type {
-	return n.Value.GetType()
-}
-
-// FunctionCallNode para chamadas de função
-type FunctionCallNode struct {
-	Name      string
-	Arguments []Node
+	return nodes
 }
 
-func (n *FunctionCallNode) Evaluate(vars map[string]interface{}) interface{} {
-	if fnValue, exists := vars[n.Name]; exists {
-		if fn, ok := fnValue.(*FunctionNode); ok {
-			// Verificação de tipos dos argumentos
-			if len(n.Arguments) != len(fn.Parameters) {
-				panic(fmt.Sprintf("Número incorreto de argumentos para função %s", n.Name))
-			}
-
-			// Criar ambiente local para a função
-			localVars := make(map[string]interface{})
-			for k, v := range vars {
-				localVars[k] = v
-			}
-
-			// Avaliar argumentos e associá-los aos parâmetros
-			for i, argNode := range n.Arguments {
-				argValue := argNode.Evaluate(vars)
-				argType := argNode.GetType()
-
-				// Verificar se o tipo do argumento é compatível com o tipo do parâmetro
-				if fn.ParamTypes[i] != TypeAny && fn.ParamTypes[i] != argType {
-					panic(fmt.Sprintf("Tipo incorreto para argumento %d da função %s: esperado %s, recebido %s",
-						i+1, n.Name, fn.ParamTypes[i], argType))
-				}
-
-				localVars[fn.Parameters[i]] = argValue
-			}
-
-			// Executar o corpo da função
-			var result interface{}
-			for _, node := range fn.Body {
-				if returnNode, ok := node.(*ReturnNode); ok {
-					// Se encontrar um return, avalia e retorna
-					returnValue := returnNode.Value.Evaluate(localVars)
-					returnType := returnNode.GetType()
-
-					// Verificar se o tipo de retorno é compatível
-					if fn.ReturnType != TypeAny && fn.ReturnType != returnType {
-						panic(fmt.Sprintf("Tipo de retorno incorreto para função %s: esperado %s, recebido %s",
-							n.Name, fn.ReturnType, returnType))
-					}
-
-					return returnValue
-				}
-				// Avaliar cada nó no corpo da função
-				nodeResult := node.Evaluate(localVars)
-
-				// Se o nó for um PrintNode, ele já imprimiu seu conteúdo
-				// Só precisamos atualizar o resultado para o próximo nó
-				result = nodeResult
-			}
-			return result
+// Next analisa e retorna a próxima instrução, lendo apenas os tokens que ela
+// ocupa. Retorna nil no fim do input.
+func (p *Parser) Next() ast.Node {
+	for p.currentToken().Type != lexer.TokenEOF {
+		if node := p.parseStatement(); node != nil {
+			return node
 		}
+		p.unexpected()
 	}
 	return nil
 }
 
-func (n *FunctionCallNode) GetType() Type {
-	// Fixed: Vars is not globally defined, should be passed as parameter
-	// This returns a conservative TypeAny since actual type checking happens during evaluation
-	return TypeAny
-}
-
-// MainNode para a função main.
-type MainNode struct {
-	Body []Node
-}
-
-func (n *MainNode) Evaluate(vars map[string]interface{}) interface{} {
-	for _, node := range n.Body {
-		node.Evaluate(vars)
-	}
-	return nil
-}
-
-func (n *MainNode) GetType() Type {
-	return TypeAny
-}
-
-// TryCatchNode para try-catch.
-type TryCatchNode struct {
-	TryBody   []Node
-	CatchBody []Node
+// unexpected rejeita o token atual, que não começa uma instrução (um } ou =
+// a mais). O token não é ignorado: o programa seria executado sem ele, e a
+// formatação o removeria sem aviso.
+func (p *Parser) unexpected() {
+	token := p.currentToken()
+	panic(fmt.Sprintf("Token inesperado %s (valor: %s)", token.Type, token.Value))
 }
 
-func (n *TryCatchNode) Evaluate(vars map[string]interface{}) interface{} {
-	for _, node := range n.TryBody {
-		if _, ok := node.(*TryCatchNode); ok {
-			for _, catchNode := range n.CatchBody {
-				return catchNode.Evaluate(vars)
-			}
-		}
-	}
-	return nil
+// Pos retorna a posição do token atual. Depois de um erro de sintaxe, é onde
+// a análise parou.
+func (p *Parser) Pos() int {
+	return p.currentToken().Pos
 }
 
-func (n *TryCatchNode) GetType() Type {
-	return TypeAny
+func (p *Parser) currentToken() lexer.Token {
+	return p.tokens.Peek(0)
 }
 
-// Parser contém o estado do parser.
-type Parser struct {
-	tokens []lexer.Token
-	pos    int
-	vars   map[string]Type // Armazenar tipos de variáveis
+// peekToken retorna o token n posições depois do atual
+func (p *Parser) peekToken(n int) lexer.Token {
+	return p.tokens.Peek(n)
 }
 
-// NewParser cria um novo parser.
-func NewParser(tokens []lexer.Token) *Parser {
-	return &Parser{
-		tokens: tokens,
-		pos:    0,
-		vars:   make(map[string]Type),
-	}
+// advance consome o token atual
+func (p *Parser) advance() lexer.Token {
+	token := p.tokens.NextToken()
+	p.end = token.End
+	return token
 }
 
-// Parse analisa os tokens e retorna a AST.
-func (p *Parser) Parse() []Node {
-	var nodes []Node
-	for p.currentToken().Type != lexer.TokenEOF {
-		node := p.parseStatement()
-		if node != nil {
-			nodes = append(nodes, node)
-		} else {
-			p.pos++ // Avança para evitar loop infinito
-		}
-	}
-	return nodes
-}
-
-func (p *Parser) currentToken() lexer.Token {
-	if p.pos >= len(p.tokens) {
-		return lexer.Token{Type: lexer.TokenEOF, Value: ""}
-	}
-	return p.tokens[p.pos]
+// spanFrom retorna o trecho do código de pos até o fim do último token
+// consumido
+func (p *Parser) spanFrom(pos int) ast.Span {
+	return ast.Span{Pos: pos, End: p.end}
 }
 
 func (p *Parser) consume(typ lexer.TokenType) lexer.Token {
 	if p.currentToken().Type != typ {
 		panic(fmt.Sprintf("Esperado %s, encontrado %s (valor: %s)", typ, p.currentToken().Type, p.currentToken().Value))
 	}
-	token := p.currentToken()
-	p.pos++
-	return token
+	return p.advance()
 }
 
-func (p *Parser) parseStatement() Node {
+func (p *Parser) parseStatement() ast.Node {
 	switch p.currentToken().Type {
 	case lexer.TokenPrint:
 		return p.parsePrint()
@@ -417,65 +111,49 @@ func (p *Parser) parseStatement() Node {
 		return p.parseAssign()
 	case lexer.TokenMain:
 		return p.parseMain()
-	case lexer.TokenTryStart:
+	case lexer.TokenTryStart, lexer.TokenTry:
 		return p.parseTryCatch()
 	case lexer.TokenFunction:
 		return p.parseFunction()
 	case lexer.TokenReturn:
 		return p.parseReturn()
 	case lexer.TokenIdentifier:
-		if p.pos+1 < len(p.tokens) && p.tokens[p.pos+1].Type == lexer.TokenEqual {
-			return p.parseEqual()
+		if _, ok := ast.CompoundOperators[p.peekToken(1).Type]; ok {
+			return p.parseCompoundAssign()
 		}
-		if p.pos+1 < len(p.tokens) && p.tokens[p.pos+1].Type == lexer.TokenLParen {
-			return p.parseFunctionCall()
-		}
-		// Se for apenas um identificador, é uma referência a variável
-		name := p.consume(lexer.TokenIdentifier).Value
-		return &VariableNode{Name: name}
+		// Qualquer outro uso de identificador é uma expressão (variável,
+		// chamada de função, comparação, ...)
+		return p.parseExpression()
+	case lexer.TokenNumber, lexer.TokenString, lexer.TokenBoolean, lexer.TokenNot, lexer.TokenLParen,
+		lexer.TokenMinus, lexer.TokenPlus, lexer.TokenInput,
+		lexer.TokenTypeNumber, lexer.TokenTypeString, lexer.TokenTypeBool:
+		return p.parseExpression()
 	default:
-		return nil // Ignora tokens desconhecidos
+		return nil // Não começa uma instrução
 	}
 }
 
-func (p *Parser) parsePrint() Node {
+// parsePrint analisa 🖨️ seguido de uma ou mais expressões separadas por
+// vírgula
+func (p *Parser) parsePrint() ast.Node {
+	start := p.currentToken().Pos
 	p.consume(lexer.TokenPrint)
 
-	if p.currentToken().Type != lexer.TokenString {
-		panic(fmt.Sprintf("Esperado STRING após PRINT, encontrado %s (valor: %s)", p.currentToken().Type, p.currentToken().Value))
-	}
-
-	// Obter o texto da string
-	text := p.consume(lexer.TokenString).Value
-
-	// Verificar se tem interpolação
-	interpolated := false
-	variables := make(map[string]struct{})
-
-	// Usar regex para encontrar todas as ocorrências de 💱{varname}
-	interpolationRegex := regexp.MustCompile(`💱\{([a-zA-Z0-9_]+)\}`)
-	matches := interpolationRegex.FindAllStringSubmatch(text, -1)
-
-	if len(matches) > 0 {
-		interpolated = true
-		for _, match := range matches {
-			variables[match[1]] = struct{}{}
-		}
-	}
-
-	return &PrintNode{
-		Text:         text,
-		Interpolated: interpolated,
-		Variables:    variables,
+	values := []ast.Node{p.parseExpression()}
+	for p.currentToken().Type == lexer.TokenComma {
+		p.consume(lexer.TokenComma)
+		values = append(values, p.parseExpression())
 	}
+	return &ast.PrintNode{Span: p.spanFrom(start), Values: values}
 }
 
-func (p *Parser) parseAssign() Node {
+func (p *Parser) parseAssign() ast.Node {
+	start := p.currentToken().Pos
 	p.consume(lexer.TokenAssign)
 	name := p.consume(lexer.TokenIdentifier).Value
 
 	// Verificar se há uma declaração de tipo explícita
-	var declaredType Type = TypeAny
+	var declaredType ast.Type = ast.TypeAny
 	if p.currentToken().Type == lexer.TokenTypeColon {
 		p.consume(lexer.TokenTypeColon)
 		declaredType = p.parseTypeAnnotation()
@@ -483,167 +161,164 @@ func (p *Parser) parseAssign() Node {
 
 	p.consume(lexer.TokenEqualSign)
 
-	// Verificar se é uma função sendo chamada ou uma expressão com múltiplos operandos
-	if p.currentToken().Type == lexer.TokenIdentifier && p.pos+1 < len(p.tokens) && p.tokens[p.pos+1].Type == lexer.TokenLParen {
-		functionCall := p.parseFunctionCall()
-		// Armazenar o tipo da variável
-		p.vars[name] = functionCall.GetType()
-		return &AssignNode{Name: name, Value: functionCall, DeclaredType: declaredType}
-	}
-
-	// Verificar se é um valor literal (string, número, booleano) ou uma expressão
-	if p.currentToken().Type == lexer.TokenString {
-		value := p.consume(lexer.TokenString).Value
-		// Inferir tipo como string
-		inferredType := TypeString
-
-		// Verificar compatibilidade de tipos
-		if declaredType != TypeAny && declaredType != inferredType {
-			panic(fmt.Sprintf("Erro de tipo: variável %s declarada como %s, mas recebeu valor de tipo %s",
-				name, declaredType, inferredType))
-		}
-
-		// Armazenar o tipo da variável
-		p.vars[name] = inferredType
-		return &AssignNode{Name: name, Value: value, DeclaredType: declaredType, InferredType: inferredType}
-	}
-
-	if p.currentToken().Type == lexer.TokenNumber {
-		strValue := p.consume(lexer.TokenNumber).Value
-		value, err := strconv.Atoi(strValue)
-		if err != nil {
-			panic(fmt.Sprintf("Número inválido: %s", strValue))
-		}
-
-		// Inferir tipo como número
-		inferredType := TypeNumber
-
-		// Verificar compatibilidade de tipos
-		if declaredType != TypeAny && declaredType != inferredType {
-			panic(fmt.Sprintf("Erro de tipo: variável %s declarada como %s, mas recebeu valor de tipo %s",
-				name, declaredType, inferredType))
-		}
+	// O valor é sempre uma expressão: literal, variável, chamada de função,
+	// operação binária ou lógica
+	expr := p.parseExpression()
+	inferredType := expr.GetType()
 
-		// Armazenar o tipo da variável
-		p.vars[name] = inferredType
-		return &AssignNode{Name: name, Value: value, DeclaredType: declaredType, InferredType: inferredType}
+	// Verificar compatibilidade de tipos quando o tipo do valor já é conhecido
+	if declaredType != ast.TypeAny && inferredType != ast.TypeAny && declaredType != inferredType {
+		panic(fmt.Sprintf("Erro de tipo: variável %s declarada como %s, mas recebeu valor de tipo %s",
+			name, declaredType, inferredType))
 	}
 
-	if p.currentToken().Type == lexer.TokenBoolean {
-		boolValue := p.consume(lexer.TokenBoolean).Value == "true"
-
-		// Inferir tipo como boolean
-		inferredType := TypeBool
-
-		// Verificar compatibilidade de tipos
-		if declaredType != TypeAny && declaredType != inferredType {
-			panic(fmt.Sprintf("Erro de tipo: variável %s declarada como %s, mas recebeu valor de tipo %s",
-				name, declaredType, inferredType))
-		}
-
-		// Armazenar o tipo da variável
+	// Armazenar o tipo da variável
+	if declaredType != ast.TypeAny {
+		p.vars[name] = declaredType
+	} else {
 		p.vars[name] = inferredType
-		return &AssignNode{Name: name, Value: boolValue, DeclaredType: declaredType, InferredType: inferredType}
 	}
-
-	// Se não for um literal, tenta parsear como expressão
-	expr := p.parseExpression()
-	// Armazenar o tipo da variável
-	p.vars[name] = expr.GetType()
-	return &AssignNode{Name: name, Value: expr, DeclaredType: declaredType}
+	return &ast.AssignNode{Span: p.spanFrom(start), Name: name, Value: expr, DeclaredType: declaredType, InferredType: inferredType}
 }
 
 // parseTypeAnnotation analisa uma anotação de tipo
-func (p *Parser) parseTypeAnnotation() Type {
+func (p *Parser) parseTypeAnnotation() ast.Type {
 	switch p.currentToken().Type {
 	case lexer.TokenTypeNumber:
 		p.consume(lexer.TokenTypeNumber)
-		return TypeNumber
+		return ast.TypeNumber
 	case lexer.TokenTypeString:
 		p.consume(lexer.TokenTypeString)
-		return TypeString
+		return ast.TypeString
 	case lexer.TokenTypeBool:
 		p.consume(lexer.TokenTypeBool)
-		return TypeBool
+		return ast.TypeBool
 	case lexer.TokenTypeAny:
 		p.consume(lexer.TokenTypeAny)
-		return TypeAny
+		return ast.TypeAny
 	default:
 		panic(fmt.Sprintf("Anotação de tipo inválida: %s", p.currentToken().Value))
 	}
 }
 
-func (p *Parser) parseEqual() Node {
+// parseCompoundAssign analisa x += expr, x -= expr, x *= expr, x .= expr,
+// x++ e x--
+func (p *Parser) parseCompoundAssign() ast.Node {
+	start := p.currentToken().Pos
 	name := p.consume(lexer.TokenIdentifier).Value
-	p.consume(lexer.TokenEqual)
-	if p.currentToken().Type == lexer.TokenString {
-		value := p.consume(lexer.TokenString).Value
-		return &EqualNode{Name: name, Value: value}
+	opToken := p.currentToken()
+	op := opToken.Type
+	p.advance()
+
+	varType, exists := p.vars[name]
+	if !exists {
+		panic(fmt.Sprintf("Variável %s não definida", name))
 	}
-	value, err := strconv.Atoi(p.consume(lexer.TokenNumber).Value)
-	if err != nil {
-		panic(fmt.Sprintf("Número inválido: %s", p.currentToken().Value))
+
+	node := &ast.CompoundAssignNode{Name: name, Op: op, Type: varType}
+	if op != lexer.TokenIncrement && op != lexer.TokenDecrement {
+		node.Value = p.parseExpression()
+	}
+
+	node.Span = p.spanFrom(start)
+
+	// Como na atribuição com ✍️, o resultado deve ter o tipo conhecido da
+	// variável: s += 1 com s texto, ou x .= "a" com x número, é um erro
+	resultType := node.GetType()
+	if varType == ast.TypeString && resultType == ast.TypeNumber {
+		panic(fmt.Sprintf("Erro de tipo: operador %s exige número, mas a variável %s é de tipo %s",
+			opToken.Value, name, varType))
 	}
-	return &EqualNode{Name: name, Value: value}
+	if varType != ast.TypeAny && varType != resultType {
+		panic(fmt.Sprintf("Erro de tipo: variável %s declarada como %s, mas recebeu valor de tipo %s",
+			name, varType, resultType))
+	}
+
+	// Uma variável de tipo desconhecido passa a ter o tipo da operação
+	p.vars[name] = resultType
+	return node
 }
 
-func (p *Parser) parseMain() Node {
+func (p *Parser) parseMain() ast.Node {
+	start := p.currentToken().Pos
 	p.consume(lexer.TokenMain)
 	p.consume(lexer.TokenAssign) // ◀️ tratado como ASSIGN
 	p.consume(lexer.TokenAssign)
 	p.consume(lexer.TokenLBrace)
-	var body []Node
+	var body []ast.Node
 	for p.currentToken().Type != lexer.TokenRBrace && p.currentToken().Type != lexer.TokenEOF {
 		if node := p.parseStatement(); node != nil {
 			body = append(body, node)
+		} else {
+			p.unexpected()
 		}
 	}
 	p.consume(lexer.TokenRBrace)
-	return &MainNode{Body: body}
+	return &ast.MainNode{Span: p.spanFrom(start), Body: body}
 }
 
-func (p *Parser) parseTryCatch() Node {
-	p.consume(lexer.TokenTryStart)
-	p.consume(lexer.TokenIdentifier) // verifyUser
-	p.consume(lexer.TokenComma)
-	p.consume(lexer.TokenNumber) // 2
+// parseTryCatch analisa [🚀 nome, tentativas] 👨🏿‍💻 { ... } 🤦🏿‍♂️ [erro] { ... }.
+// O cabeçalho 🚀 é opcional; sem ele o bloco try é executado uma vez.
+func (p *Parser) parseTryCatch() ast.Node {
+	tryCatch := &ast.TryCatchNode{Attempts: 1, ErrorVar: "erro"}
+	start := p.currentToken().Pos
+	if p.currentToken().Type == lexer.TokenTryStart {
+		p.consume(lexer.TokenTryStart)
+		tryCatch.Label = p.consume(lexer.TokenIdentifier).Value // verifyUser
+		p.consume(lexer.TokenComma)
+		attempts := p.consume(lexer.TokenNumber).Value // 2
+		tryCatch.Attempts, _ = strconv.Atoi(attempts)
+	}
 	p.consume(lexer.TokenTry)
 	p.consume(lexer.TokenLBrace)
-	var tryBody []Node
+	var tryBody []ast.Node
 	for p.currentToken().Type != lexer.TokenRBrace && p.currentToken().Type != lexer.TokenEOF {
 		if node := p.parseStatement(); node != nil {
 			tryBody = append(tryBody, node)
+		} else {
+			p.unexpected()
 		}
 	}
 	p.consume(lexer.TokenRBrace)
 	p.consume(lexer.TokenCatch)
+	if p.currentToken().Type == lexer.TokenIdentifier {
+		tryCatch.ErrorVar = p.consume(lexer.TokenIdentifier).Value
+	}
 	p.consume(lexer.TokenLBrace)
-	var catchBody []Node
+
+	// A variável de erro é uma string dentro do catch
+	p.vars[tryCatch.ErrorVar] = ast.TypeString
+	var catchBody []ast.Node
 	for p.currentToken().Type != lexer.TokenRBrace && p.currentToken().Type != lexer.TokenEOF {
 		if node := p.parseStatement(); node != nil {
 			catchBody = append(catchBody, node)
+		} else {
+			p.unexpected()
 		}
 	}
 	p.consume(lexer.TokenRBrace)
-	return &TryCatchNode{TryBody: tryBody, CatchBody: catchBody}
+	tryCatch.TryBody, tryCatch.CatchBody = tryBody, catchBody
+	tryCatch.Span = p.spanFrom(start)
+	return tryCatch
 }
 
 // parseFunction analisa uma definição de função
-func (p *Parser) parseFunction() Node {
+func (p *Parser) parseFunction() ast.Node {
+	start := p.currentToken().Pos
 	p.consume(lexer.TokenFunction)
 	name := p.consume(lexer.TokenIdentifier).Value
 	p.consume(lexer.TokenLParen)
 
 	// Analisar parâmetros e seus tipos
 	var params []string
-	var paramTypes []Type
+	var paramTypes []ast.Type
 
 	if p.currentToken().Type != lexer.TokenRParen {
 		paramName := p.consume(lexer.TokenIdentifier).Value
 		params = append(params, paramName)
 
 		// Verificar se tem anotação de tipo
-		var paramType Type = TypeAny
+		var paramType ast.Type = ast.TypeAny
 		if p.currentToken().Type == lexer.TokenTypeColon {
 			p.consume(lexer.TokenTypeColon)
 			paramType = p.parseTypeAnnotation()
@@ -656,7 +331,7 @@ func (p *Parser) parseFunction() Node {
 			params = append(params, paramName)
 
 			// Verificar se tem anotação de tipo
-			paramType = TypeAny
+			paramType = ast.TypeAny
 			if p.currentToken().Type == lexer.TokenTypeColon {
 				p.consume(lexer.TokenTypeColon)
 				paramType = p.parseTypeAnnotation()
@@ -667,7 +342,7 @@ func (p *Parser) parseFunction() Node {
 	p.consume(lexer.TokenRParen)
 
 	// Verificar tipo de retorno
-	var returnType Type = TypeAny
+	var returnType ast.Type = ast.TypeAny
 	if p.currentToken().Type == lexer.TokenTypeColon {
 		p.consume(lexer.TokenTypeColon)
 		returnType = p.parseTypeAnnotation()
@@ -675,16 +350,30 @@ func (p *Parser) parseFunction() Node {
 
 	p.consume(lexer.TokenLBrace)
 
+	// Os parâmetros são visíveis apenas dentro do corpo da função
+	outerVars := p.vars
+	p.vars = make(map[string]ast.Type, len(outerVars)+len(params))
+	for k, v := range outerVars {
+		p.vars[k] = v
+	}
+	for i, param := range params {
+		p.vars[param] = paramTypes[i]
+	}
+
 	// Analisar corpo da função
-	var body []Node
+	var body []ast.Node
 	for p.currentToken().Type != lexer.TokenRBrace && p.currentToken().Type != lexer.TokenEOF {
 		if node := p.parseStatement(); node != nil {
 			body = append(body, node)
+		} else {
+			p.unexpected()
 		}
 	}
 	p.consume(lexer.TokenRBrace)
+	p.vars = outerVars
 
-	return &FunctionNode{
+	return &ast.FunctionNode{
+		Span:       p.spanFrom(start),
 		Name:       name,
 		Parameters: params,
 		ParamTypes: paramTypes,
@@ -694,18 +383,20 @@ func (p *Parser) parseFunction() Node {
 }
 
 // parseReturn analisa uma expressão de retorno
-func (p *Parser) parseReturn() Node {
-	p.consume(lexer.TokenReturn)
-	return &ReturnNode{Value: p.parseExpression()}
+func (p *Parser) parseReturn() ast.Node {
+	start := p.consume(lexer.TokenReturn).Pos
+	value := p.parseExpression()
+	return &ast.ReturnNode{Span: p.spanFrom(start), Value: value}
 }
 
 // parseFunctionCall analisa uma chamada de função
-func (p *Parser) parseFunctionCall() Node {
+func (p *Parser) parseFunctionCall() ast.Node {
+	start := p.currentToken().Pos
 	name := p.consume(lexer.TokenIdentifier).Value
 	p.consume(lexer.TokenLParen)
 
 	// Analisar argumentos
-	var args []Node
+	var args []ast.Node
 	if p.currentToken().Type != lexer.TokenRParen {
 		args = append(args, p.parseExpression())
 		for p.currentToken().Type == lexer.TokenComma {
@@ -715,77 +406,280 @@ func (p *Parser) parseFunctionCall() Node {
 	}
 	p.consume(lexer.TokenRParen)
 
-	return &FunctionCallNode{Name: name, Arguments: args}
+	return &ast.FunctionCallNode{Span: p.spanFrom(start), Name: name, Arguments: args}
+}
+
+// parseExpression analisa uma expressão completa. A precedência, da menor
+// para a maior, é: or, and, not, 🟰, |, ^, &, << e >>, concatenação (.),
+// soma/subtração, multiplicação e sinais unários (-, +).
+func (p *Parser) parseExpression() ast.Node {
+	return p.parseOr()
 }
 
-// parseExpression analisa uma expressão matemática ou variável
-func (p *Parser) parseExpression() Node {
-	left := p.parseTerm()
+// parseOr analisa expressões ligadas por or (🔀)
+func (p *Parser) parseOr() ast.Node {
+	left := p.parseAnd()
+	for p.currentToken().Type == lexer.TokenOr {
+		p.advance()
+		right := p.parseAnd()
+		left = &ast.LogicalNode{Span: p.spanFrom(left.GetSpan().Pos), Left: left, Op: lexer.TokenOr, Right: right}
+	}
+	return left
+}
+
+// parseAnd analisa expressões ligadas por and (🤝)
+func (p *Parser) parseAnd() ast.Node {
+	left := p.parseNot()
+	for p.currentToken().Type == lexer.TokenAnd {
+		p.advance()
+		right := p.parseNot()
+		left = &ast.LogicalNode{Span: p.spanFrom(left.GetSpan().Pos), Left: left, Op: lexer.TokenAnd, Right: right}
+	}
+	return left
+}
+
+// parseNot analisa a negação lógica not (🚫)
+func (p *Parser) parseNot() ast.Node {
+	if p.currentToken().Type == lexer.TokenNot {
+		start := p.advance().Pos
+		operand := p.parseNot()
+		return &ast.UnaryOpNode{Span: p.spanFrom(start), Op: lexer.TokenNot, Operand: operand}
+	}
+	return p.parseEquality()
+}
+
+// parseEquality analisa comparações com 🟰 entre duas expressões
+func (p *Parser) parseEquality() ast.Node {
+	left := p.parseBitOr()
+	for p.currentToken().Type == lexer.TokenEqual {
+		p.advance()
+		right := p.parseBitOr()
+		left = &ast.EqualNode{Span: p.spanFrom(left.GetSpan().Pos), Left: left, Right: right}
+	}
+	return left
+}
+
+// parseBitOr analisa o ou bit a bit |
+func (p *Parser) parseBitOr() ast.Node {
+	left := p.parseBitXor()
+	for p.currentToken().Type == lexer.TokenBitOr {
+		p.advance()
+		right := p.parseBitXor()
+		left = &ast.BinaryOpNode{Span: p.spanFrom(left.GetSpan().Pos), Left: left, Op: lexer.TokenBitOr, Right: right}
+	}
+	return left
+}
+
+// parseBitXor analisa o ou exclusivo bit a bit ^
+func (p *Parser) parseBitXor() ast.Node {
+	left := p.parseBitAnd()
+	for p.currentToken().Type == lexer.TokenBitXor {
+		p.advance()
+		right := p.parseBitAnd()
+		left = &ast.BinaryOpNode{Span: p.spanFrom(left.GetSpan().Pos), Left: left, Op: lexer.TokenBitXor, Right: right}
+	}
+	return left
+}
+
+// parseBitAnd analisa o e bit a bit &
+func (p *Parser) parseBitAnd() ast.Node {
+	left := p.parseShift()
+	for p.currentToken().Type == lexer.TokenBitAnd {
+		p.advance()
+		right := p.parseShift()
+		left = &ast.BinaryOpNode{Span: p.spanFrom(left.GetSpan().Pos), Left: left, Op: lexer.TokenBitAnd, Right: right}
+	}
+	return left
+}
+
+// parseShift analisa deslocamentos de bits << e >>
+func (p *Parser) parseShift() ast.Node {
+	left := p.parseConcat()
+	for p.currentToken().Type == lexer.TokenShiftLeft || p.currentToken().Type == lexer.TokenShiftRight {
+		operator := p.currentToken()
+		p.advance()
+		right := p.parseConcat()
+		left = &ast.BinaryOpNode{Span: p.spanFrom(left.GetSpan().Pos), Left: left, Op: operator.Type, Right: right}
+	}
+	return left
+}
 
+// parseConcat analisa concatenações de strings
+func (p *Parser) parseConcat() ast.Node {
+	left := p.parseAdditive()
+	for p.currentToken().Type == lexer.TokenConcat {
+		p.advance()
+		right := p.parseAdditive()
+		left = &ast.BinaryOpNode{Span: p.spanFrom(left.GetSpan().Pos), Left: left, Op: lexer.TokenConcat, Right: right}
+	}
+	return left
+}
+
+// parseAdditive analisa somas e subtrações
+func (p *Parser) parseAdditive() ast.Node {
+	left := p.parseMultiplicative()
 	for p.currentToken().Type == lexer.TokenPlus ||
-		p.currentToken().Type == lexer.TokenMult ||
-		p.currentToken().Type == lexer.TokenNumPlus ||
-		p.currentToken().Type == lexer.TokenConcat {
+		p.currentToken().Type == lexer.TokenMinus ||
+		p.currentToken().Type == lexer.TokenNumPlus {
 
 		operator := p.currentToken()
-		p.pos++
-		right := p.parseTerm()
-		left = &BinaryOpNode{Left: left, Op: operator.Type, Right: right}
+		p.advance()
+		right := p.parseMultiplicative()
+		left = &ast.BinaryOpNode{Span: p.spanFrom(left.GetSpan().Pos), Left: left, Op: operator.Type, Right: right}
 	}
+	return left
+}
 
+// parseMultiplicative analisa multiplicações
+func (p *Parser) parseMultiplicative() ast.Node {
+	left := p.parseUnary()
+	for p.currentToken().Type == lexer.TokenMult {
+		p.advance()
+		right := p.parseUnary()
+		left = &ast.BinaryOpNode{Span: p.spanFrom(left.GetSpan().Pos), Left: left, Op: lexer.TokenMult, Right: right}
+	}
 	return left
 }
 
-// parseTerm analisa um termo (variável, número, string ou boolean)
-func (p *Parser) parseTerm() Node {
+// parseUnary analisa os sinais unários - e +, que têm precedência maior que
+// a multiplicação: -a * b é (-a) * b
+func (p *Parser) parseUnary() ast.Node {
+	switch op := p.currentToken().Type; op {
+	case lexer.TokenMinus, lexer.TokenPlus, lexer.TokenNumPlus:
+		if op == lexer.TokenMinus && p.peekToken(1).Type == lexer.TokenNumber {
+			// Literal negativo: -9223372036854775808 cabe em um int, mas
+			// 9223372036854775808 sozinho não
+			start := p.advance().Pos
+			number := p.parseNumber("-")
+			number.Span.Pos = start
+			return number
+		}
+		start := p.advance().Pos
+		operand := p.parseUnary()
+		return &ast.UnaryOpNode{Span: p.spanFrom(start), Op: op, Operand: operand}
+	}
+	return p.parseTerm()
+}
+
+// parseTerm analisa um termo (variável, número, string, boolean, leitura da
+// entrada, conversão de tipo ou expressão entre parênteses)
+func (p *Parser) parseTerm() ast.Node {
+	if p.currentToken().Type == lexer.TokenLParen {
+		p.consume(lexer.TokenLParen)
+		expr := p.parseExpression()
+		p.consume(lexer.TokenRParen)
+		return expr
+	}
+
 	if p.currentToken().Type == lexer.TokenIdentifier {
-		if p.pos+1 < len(p.tokens) && p.tokens[p.pos+1].Type == lexer.TokenLParen {
+		if p.peekToken(1).Type == lexer.TokenLParen {
 			return p.parseFunctionCall()
 		}
-		name := p.consume(lexer.TokenIdentifier).Value
-		return &VariableNode{Name: name, Type: p.vars[name]}
+		token := p.consume(lexer.TokenIdentifier)
+		return &ast.VariableNode{Span: p.spanFrom(token.Pos), Name: token.Value, Type: p.varType(token.Value)}
 	}
 
 	if p.currentToken().Type == lexer.TokenNumber {
-		value, _ := strconv.Atoi(p.consume(lexer.TokenNumber).Value)
-		return &AssignNode{Value: value, DeclaredType: TypeNumber, InferredType: TypeNumber}
+		return p.parseNumber("")
 	}
 
 	if p.currentToken().Type == lexer.TokenString {
-		value := p.consume(lexer.TokenString).Value
-		return &StringLiteralNode{Value: value}
+		return p.parseStringLiteral()
 	}
 
 	if p.currentToken().Type == lexer.TokenBoolean {
-		value := p.consume(lexer.TokenBoolean).Value == "true"
-		return &BooleanLiteralNode{Value: value}
+		token := p.consume(lexer.TokenBoolean)
+		return &ast.BooleanLiteralNode{Span: p.spanFrom(token.Pos), Value: token.Value == "true"}
+	}
+
+	if p.currentToken().Type == lexer.TokenInput {
+		return p.parseInput()
+	}
+
+	if conversionTypes[p.currentToken().Type] != "" && p.peekToken(1).Type == lexer.TokenLParen {
+		token := p.advance()
+		p.consume(lexer.TokenLParen)
+		value := p.parseExpression()
+		p.consume(lexer.TokenRParen)
+		return &ast.ConvertNode{Span: p.spanFrom(token.Pos), To: conversionTypes[token.Type], Value: value}
 	}
 
 	panic(fmt.Sprintf("Termo inesperado: %s", p.currentToken().Value))
 }
 
-// StringLiteralNode representa uma string literal
-type StringLiteralNode struct {
-	Value string
+// parseInput analisa ⌨️, ⌨️() ou ⌨️(prompt)
+func (p *Parser) parseInput() ast.Node {
+	start := p.consume(lexer.TokenInput).Pos
+	node := &ast.InputNode{}
+	if p.currentToken().Type == lexer.TokenLParen {
+		p.consume(lexer.TokenLParen)
+		if p.currentToken().Type != lexer.TokenRParen {
+			node.Prompt = p.parseExpression()
+		}
+		p.consume(lexer.TokenRParen)
+	}
+	node.Span = p.spanFrom(start)
+	return node
 }
 
-func (n *StringLiteralNode) Evaluate(vars map[string]interface{}) interface{} {
-	return n.Value
+// conversionTypes associa os tokens de tipo usados como funções de conversão
+// (🔢("42"), ⚖️("true"), 📝(42)) ao tipo resultante
+var conversionTypes = map[lexer.TokenType]ast.Type{
+	lexer.TokenTypeNumber: ast.TypeNumber,
+	lexer.TokenTypeString: ast.TypeString,
+	lexer.TokenTypeBool:   ast.TypeBool,
 }
 
-func (n *StringLiteralNode) GetType() Type {
-	return TypeString
+// parseNumber analisa um número literal, precedido do sinal informado
+func (p *Parser) parseNumber(sign string) *ast.NumberLiteralNode {
+	token := p.consume(lexer.TokenNumber)
+	strValue := sign + token.Value
+	value, err := lexer.ParseNumber(strValue)
+	if errors.Is(err, strconv.ErrRange) {
+		panic(fmt.Sprintf("Número fora do intervalo de inteiros: %s", strValue))
+	}
+	if err != nil {
+		panic(fmt.Sprintf("Número inválido: %s", strValue))
+	}
+	return &ast.NumberLiteralNode{Span: p.spanFrom(token.Pos), Value: value}
 }
 
-// BooleanLiteralNode representa um valor booleano literal
-type BooleanLiteralNode struct {
-	Value bool
-}
+// parseStringLiteral analisa uma string, que pode conter interpolações. O
+// lexer entrega strings interpoladas como uma sequência
+// STRING (INTERPOLATE { expressão [FORMAT_SPEC] } STRING)*.
+func (p *Parser) parseStringLiteral() ast.Node {
+	first := p.consume(lexer.TokenString)
+	if p.currentToken().Type != lexer.TokenInterpolate {
+		return &ast.StringLiteralNode{Span: p.spanFrom(first.Pos), Value: first.Value}
+	}
+
+	var parts []ast.Node
+	if first.Value != "" {
+		parts = append(parts, &ast.StringLiteralNode{Span: p.spanFrom(first.Pos), Value: first.Value})
+	}
+	for p.currentToken().Type == lexer.TokenInterpolate {
+		start := p.consume(lexer.TokenInterpolate).Pos
+		p.consume(lexer.TokenLBrace)
+		interpolation := &ast.InterpolationNode{Expr: p.parseExpression()}
+		if p.currentToken().Type == lexer.TokenFormatSpec {
+			interpolation.Format = p.consume(lexer.TokenFormatSpec).Value
+		}
+		p.consume(lexer.TokenRBrace)
+		interpolation.Span = p.spanFrom(start)
+		parts = append(parts, interpolation)
 
-func (n *BooleanLiteralNode) Evaluate(vars map[string]interface{}) interface{} {
-	return n.Value
+		if text := p.consume(lexer.TokenString); text.Value != "" {
+			parts = append(parts, &ast.StringLiteralNode{Span: p.spanFrom(text.Pos), Value: text.Value})
+		}
+	}
+	return &ast.InterpolatedStringNode{Span: p.spanFrom(first.Pos), Parts: parts}
 }
 
-func (n *BooleanLiteralNode) GetType() Type {
-	return TypeBool
+// varType retorna o tipo conhecido de uma variável, ou TypeAny se ela ainda
+// não foi vista pelo parser
+func (p *Parser) varType(name string) ast.Type {
+	if t, exists := p.vars[name]; exists {
+		return t
+	}
+	return ast.TypeAny
 }
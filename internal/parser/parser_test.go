@@ -0,0 +1,50 @@
+package parser
+
+import (
+	"fmt"
+	"melhorzin-lang/internal/lexer"
+	"strings"
+	"testing"
+)
+
+// parse analisa source e retorna a mensagem do erro de sintaxe, ou "" se
+// não houve erro
+func parse(source string) (message string) {
+	defer func() {
+		if r := recover(); r != nil {
+			message = fmt.Sprint(r)
+		}
+	}()
+	NewParser(lexer.NewLexer(source)).Parse()
+	return ""
+}
+
+func TestCompoundAssignTypes(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		err    string // Trecho da mensagem de erro esperada; vazio se não há erro
+	}{
+		{"número", "✍️ x:🔢 = 1\nx += 2\nx *= 3\nx--", ""},
+		{"texto", "✍️ s:📝 = \"a\"\ns .= \"b\"", ""},
+		{"tipo inferido", "✍️ x = 1\nx -= 1", ""},
+		{"concatenar em número", "✍️ x:🔢 = 1\nx .= \"a\"", "variável x declarada como NUMBER"},
+		{"concatenar em número inferido", "✍️ x = 1\nx .= \"a\"", "variável x declarada como NUMBER"},
+		{"somar em texto", "✍️ s = \"a\"\ns += 1", "operador += exige número"},
+		{"incrementar texto", "✍️ s:📝 = \"a\"\ns++", "operador ++ exige número"},
+		{"tipo desconhecido", "▶️ f() { ↩️ 1 }\n✍️ v = f()\nv .= \"a\"\nv .= \"b\"", ""},
+		{"tipo desconhecido fixado", "▶️ f() { ↩️ 1 }\n✍️ v = f()\nv += 1\nv .= \"a\"", "variável v declarada como NUMBER"},
+		{"variável indefinida", "x += 1", "Variável x não definida"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := parse(tt.source)
+			if tt.err == "" && got != "" {
+				t.Errorf("Parse(%q): erro inesperado %q", tt.source, got)
+			}
+			if tt.err != "" && !strings.Contains(got, tt.err) {
+				t.Errorf("Parse(%q) = erro %q, esperado erro com %q", tt.source, got, tt.err)
+			}
+		})
+	}
+}
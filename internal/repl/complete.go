@@ -0,0 +1,96 @@
+package repl
+
+import (
+	"maps"
+	"melhorzin-lang/internal/lexer"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// commands são os comandos do REPL oferecidos ao completar o início da linha
+var commands = []string{":help", ":load", ":quit", ":reset", ":type", ":vars"}
+
+// complete oferece opções para completar o texto antes do cursor:
+//
+//   - :print: vira 🖨️, e :pr completa para :print: (ou direto para 🖨️ se for
+//     a única palavra-chave com esse começo);
+//   - no início da linha, :t também completa os comandos do REPL;
+//   - um nome começado completa com as variáveis e funções definidas.
+func (r *REPL) complete(prefix string) (start int, candidates []string) {
+	emojis := lexer.EmojiKeywords()
+
+	// :palavra: completa, trocada direto pelo emoji
+	if body, ok := strings.CutSuffix(prefix, ":"); ok {
+		wordStart := identStart(body)
+		if wordStart > 0 && body[wordStart-1] == ':' && !isIdentEnd(body[:wordStart-1]) {
+			if emoji, ok := emojis[body[wordStart:]]; ok {
+				return wordStart - 1, []string{emoji}
+			}
+		}
+		return 0, nil
+	}
+
+	start = identStart(prefix)
+	word := prefix[start:]
+	if start > 0 && prefix[start-1] == ':' && !isIdentEnd(prefix[:start-1]) {
+		var emoji string
+		for text := range emojis {
+			if isIdent(text) && strings.HasPrefix(text, word) {
+				emoji = emojis[text]
+				candidates = append(candidates, ":"+text+":")
+			}
+		}
+		if strings.TrimSpace(prefix[:start-1]) == "" {
+			for _, command := range commands {
+				if strings.HasPrefix(command, ":"+word) {
+					candidates = append(candidates, command)
+				}
+			}
+		}
+		if len(candidates) == 1 && strings.HasSuffix(candidates[0], ":") {
+			candidates[0] = emoji
+		}
+		slices.Sort(candidates)
+		return start - 1, candidates
+	}
+
+	if word == "" {
+		return start, nil
+	}
+	for _, name := range slices.Sorted(maps.Keys(r.interp.Variables())) {
+		if strings.HasPrefix(name, word) {
+			candidates = append(candidates, name)
+		}
+	}
+	return start, candidates
+}
+
+// identStart retorna onde começa o identificador no fim de text
+func identStart(text string) int {
+	start := len(text)
+	for start > 0 {
+		r, size := utf8.DecodeLastRuneInString(text[:start])
+		if !isIdentRune(r) {
+			break
+		}
+		start -= size
+	}
+	return start
+}
+
+// isIdentEnd indica se text termina com um caractere de identificador, caso
+// em que um : seguinte não inicia um atalho
+func isIdentEnd(text string) bool {
+	r, _ := utf8.DecodeLastRuneInString(text)
+	return text != "" && isIdentRune(r)
+}
+
+func isIdent(text string) bool {
+	return text != "" && identStart(text) == 0
+}
+
+func isIdentRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_'
+}
@@ -0,0 +1,38 @@
+package repl
+
+import (
+	"io"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestComplete(t *testing.T) {
+	r := New(strings.NewReader(""), io.Discard)
+	r.eval("✍️ contador = 1\n✍️ conta = 2\n▶️ soma(a, b) { ↩️ a + b }")
+
+	tests := []struct {
+		prefix     string
+		start      int
+		candidates []string
+	}{
+		{":print:", 0, []string{"🖨️"}},
+		{"✍️ x = :input:", len("✍️ x = "), []string{"⌨️"}},
+		{":xyz:", 0, nil},
+		{":pri", 0, []string{"🖨️"}},               // Única palavra-chave com esse começo
+		{":r", 0, []string{":reset", ":return:"}}, // No início da linha, também os comandos
+		{"x = :r", len("x = "), []string{"↩️"}},   // Fora do início, só as palavras-chave
+		{"🖨️ so", len("🖨️ "), []string{"soma"}},
+		{"🖨️ cont", len("🖨️ "), []string{"conta", "contador"}},
+		{"🖨️ ", len("🖨️ "), nil},
+		{"a:fn", len("a:"), nil}, // : depois de um nome é anotação de tipo
+	}
+	for _, tt := range tests {
+		t.Run(tt.prefix, func(t *testing.T) {
+			start, candidates := r.complete(tt.prefix)
+			if start != tt.start || !slices.Equal(candidates, tt.candidates) {
+				t.Errorf("complete(%q) = %d, %q; esperado %d, %q", tt.prefix, start, candidates, tt.start, tt.candidates)
+			}
+		})
+	}
+}
@@ -0,0 +1,340 @@
+package repl
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+)
+
+// errInterrupted é retornado pelo editor quando o usuário aperta Ctrl-C
+// durante a edição: a entrada pendente deve ser descartada.
+var errInterrupted = errors.New("entrada interrompida")
+
+const (
+	historyFile  = ".melhorzin_history" // Arquivo de histórico, no diretório do usuário
+	historyLimit = 1000                 // Quantas entradas do histórico são mantidas
+)
+
+// completer retorna as opções para completar o texto antes do cursor e a
+// posição, em bytes, do trecho que elas substituem
+type completer func(prefix string) (start int, candidates []string)
+
+// lineEditor lê linhas de um terminal em modo raw, com edição, histórico e
+// completar com Tab.
+type lineEditor struct {
+	fd       int
+	in       *bufio.Reader
+	out      io.Writer
+	complete completer
+
+	history     []string
+	historyPath string // Vazio se não há diretório do usuário
+
+	prompt string
+	buf    []rune // Linha sendo editada
+	pos    int    // Posição do cursor em buf
+}
+
+// newLineEditor cria um editor para o terminal fd e carrega o histórico
+func newLineEditor(fd int, in *bufio.Reader, out io.Writer, complete completer) *lineEditor {
+	e := &lineEditor{fd: fd, in: in, out: out, complete: complete}
+	if home, err := os.UserHomeDir(); err == nil {
+		e.historyPath = filepath.Join(home, historyFile)
+		e.loadHistory()
+	}
+	return e
+}
+
+// readLine mostra o prompt e lê uma linha editável. Retorna io.EOF com Ctrl-D
+// em uma linha vazia e errInterrupted com Ctrl-C.
+func (e *lineEditor) readLine(prompt string) (string, error) {
+	restore, err := makeRaw(e.fd)
+	if err != nil {
+		return "", err
+	}
+	defer restore()
+
+	e.prompt, e.buf, e.pos = prompt, nil, 0
+	index := len(e.history) // Entrada do histórico sendo mostrada
+	var draft []rune        // Linha digitada antes de navegar no histórico
+	e.refresh()
+
+	for {
+		r, _, err := e.in.ReadRune()
+		if err != nil {
+			io.WriteString(e.out, "\r\n")
+			return "", err
+		}
+
+		switch r {
+		case '\r', '\n':
+			io.WriteString(e.out, "\r\n")
+			line := string(e.buf)
+			e.addHistory(line)
+			return line, nil
+		case 3: // Ctrl-C
+			io.WriteString(e.out, "^C\r\n")
+			return "", errInterrupted
+		case 4: // Ctrl-D
+			if len(e.buf) == 0 {
+				io.WriteString(e.out, "\r\n")
+				return "", io.EOF
+			}
+			e.deleteForward()
+		case 127, 8: // Backspace
+			e.deleteBackward()
+		case 1: // Ctrl-A
+			e.pos = 0
+		case 5: // Ctrl-E
+			e.pos = len(e.buf)
+		case 2: // Ctrl-B
+			e.pos = e.prevBoundary(e.pos)
+		case 6: // Ctrl-F
+			e.pos = e.nextBoundary(e.pos)
+		case 11: // Ctrl-K
+			e.buf = e.buf[:e.pos]
+		case 21: // Ctrl-U
+			e.buf = e.buf[e.pos:]
+			e.pos = 0
+		case 23: // Ctrl-W
+			e.deleteWord()
+		case 12: // Ctrl-L
+			io.WriteString(e.out, "\x1b[H\x1b[2J")
+		case 16: // Ctrl-P
+			index, draft = e.historyMove(index, -1, draft)
+		case 14: // Ctrl-N
+			index, draft = e.historyMove(index, 1, draft)
+		case '\t':
+			e.tab()
+		case 27: // ESC: sequências das setas, Home, End e Delete
+			switch e.escape() {
+			case "A":
+				index, draft = e.historyMove(index, -1, draft)
+			case "B":
+				index, draft = e.historyMove(index, 1, draft)
+			case "C":
+				e.pos = e.nextBoundary(e.pos)
+			case "D":
+				e.pos = e.prevBoundary(e.pos)
+			case "H", "1~", "7~":
+				e.pos = 0
+			case "F", "4~", "8~":
+				e.pos = len(e.buf)
+			case "3~":
+				e.deleteForward()
+			}
+		default:
+			if unicode.IsControl(r) {
+				continue
+			}
+			e.insert([]rune{r})
+		}
+		e.refresh()
+	}
+}
+
+// escape lê o restante de uma sequência de escape (ESC [ ... ou ESC O ...) e
+// retorna os parâmetros seguidos da letra final, como "A" ou "3~"
+func (e *lineEditor) escape() string {
+	kind, _, err := e.in.ReadRune()
+	if err != nil || (kind != '[' && kind != 'O') {
+		return ""
+	}
+	var seq strings.Builder
+	for {
+		r, _, err := e.in.ReadRune()
+		if err != nil {
+			return ""
+		}
+		seq.WriteRune(r)
+		if r >= 0x40 && r <= 0x7e {
+			return seq.String()
+		}
+	}
+}
+
+// refresh redesenha a linha e posiciona o cursor. A linha é escrita de novo
+// até o cursor para que o terminal calcule a largura dos emojis.
+func (e *lineEditor) refresh() {
+	io.WriteString(e.out, "\r\x1b[K"+e.prompt+string(e.buf)+"\r"+e.prompt+string(e.buf[:e.pos]))
+}
+
+// insert insere texto na posição do cursor
+func (e *lineEditor) insert(text []rune) {
+	buf := make([]rune, 0, len(e.buf)+len(text))
+	buf = append(buf, e.buf[:e.pos]...)
+	buf = append(buf, text...)
+	e.buf = append(buf, e.buf[e.pos:]...)
+	e.pos += len(text)
+}
+
+func (e *lineEditor) deleteBackward() {
+	start := e.prevBoundary(e.pos)
+	e.buf = append(e.buf[:start], e.buf[e.pos:]...)
+	e.pos = start
+}
+
+func (e *lineEditor) deleteForward() {
+	end := e.nextBoundary(e.pos)
+	e.buf = append(e.buf[:e.pos], e.buf[end:]...)
+}
+
+// deleteWord apaga a palavra antes do cursor e os espaços depois dela
+func (e *lineEditor) deleteWord() {
+	start := e.pos
+	for start > 0 && unicode.IsSpace(e.buf[start-1]) {
+		start--
+	}
+	for start > 0 && !unicode.IsSpace(e.buf[start-1]) {
+		start--
+	}
+	e.buf = append(e.buf[:start], e.buf[e.pos:]...)
+	e.pos = start
+}
+
+// Um emoji pode ocupar vários runes: seletores de variação, tons de pele,
+// marcas combinantes e o keycap se juntam ao rune anterior, e o ZWJ junta o
+// rune seguinte (👨🏿‍💻). O cursor se move e apaga um emoji inteiro de cada
+// vez.
+
+// extends indica se r se junta ao rune anterior na mesma grafia
+func extends(r rune) bool {
+	return r == 0x200d || r == 0xfe0e || r == 0xfe0f || r == 0x20e3 ||
+		(r >= 0x1f3fb && r <= 0x1f3ff) || (r >= 0xe0020 && r <= 0xe007f) ||
+		unicode.Is(unicode.Mn, r)
+}
+
+// boundary indica se há uma separação entre grafias antes de buf[i]
+func (e *lineEditor) boundary(i int) bool {
+	if i <= 0 || i >= len(e.buf) {
+		return true
+	}
+	return !extends(e.buf[i]) && e.buf[i-1] != 0x200d
+}
+
+func (e *lineEditor) prevBoundary(i int) int {
+	if i == 0 {
+		return 0
+	}
+	i--
+	for !e.boundary(i) {
+		i--
+	}
+	return i
+}
+
+func (e *lineEditor) nextBoundary(i int) int {
+	if i == len(e.buf) {
+		return i
+	}
+	i++
+	for !e.boundary(i) {
+		i++
+	}
+	return i
+}
+
+// historyMove mostra a entrada anterior (step -1) ou seguinte (step 1) do
+// histórico. A linha que estava sendo digitada é guardada em draft e volta
+// depois da última entrada.
+func (e *lineEditor) historyMove(index, step int, draft []rune) (int, []rune) {
+	next := index + step
+	if next < 0 || next > len(e.history) {
+		return index, draft
+	}
+	if index == len(e.history) {
+		draft = e.buf
+	}
+	if next == len(e.history) {
+		e.buf = draft
+	} else {
+		e.buf = []rune(e.history[next])
+	}
+	e.pos = len(e.buf)
+	return next, draft
+}
+
+// tab completa o texto antes do cursor. Com uma única opção o texto é
+// substituído; com várias ele é estendido até o prefixo comum ou, se já for o
+// prefixo comum, as opções são listadas.
+func (e *lineEditor) tab() {
+	if e.complete == nil {
+		return
+	}
+	prefix := string(e.buf[:e.pos])
+	start, candidates := e.complete(prefix)
+	if len(candidates) == 0 {
+		io.WriteString(e.out, "\a")
+		return
+	}
+
+	word := prefix[start:]
+	replacement := candidates[0]
+	if len(candidates) > 1 {
+		replacement = commonPrefix(candidates)
+		if len(replacement) <= len(word) {
+			io.WriteString(e.out, "\r\n"+strings.Join(candidates, "  ")+"\r\n")
+			return
+		}
+	}
+	wordStart := e.pos - len([]rune(word))
+	rest := append([]rune(nil), e.buf[e.pos:]...)
+	e.buf, e.pos = e.buf[:wordStart], wordStart
+	e.insert([]rune(replacement))
+	e.buf = append(e.buf, rest...)
+}
+
+// commonPrefix retorna o maior prefixo comum a todas as palavras
+func commonPrefix(words []string) string {
+	prefix := words[0]
+	for _, word := range words[1:] {
+		for !strings.HasPrefix(word, prefix) {
+			prefix = prefix[:len(prefix)-1]
+		}
+	}
+	return strings.ToValidUTF8(prefix, "")
+}
+
+// loadHistory lê as últimas entradas do arquivo de histórico. Se o arquivo
+// passou do limite, ele é reescrito só com as entradas mantidas.
+func (e *lineEditor) loadHistory() {
+	data, err := os.ReadFile(e.historyPath)
+	if err != nil {
+		return
+	}
+	lines := strings.Split(strings.TrimRight(string(data), "\n"), "\n")
+	if len(lines) > historyLimit {
+		lines = lines[len(lines)-historyLimit:]
+		os.WriteFile(e.historyPath, []byte(strings.Join(lines, "\n")+"\n"), 0o600)
+	}
+	for _, line := range lines {
+		if line != "" {
+			e.history = append(e.history, line)
+		}
+	}
+}
+
+// addHistory guarda uma linha no histórico e no arquivo, se ela não estiver
+// vazia nem repetir a anterior
+func (e *lineEditor) addHistory(line string) {
+	if strings.TrimSpace(line) == "" || (len(e.history) > 0 && e.history[len(e.history)-1] == line) {
+		return
+	}
+	e.history = append(e.history, line)
+	if len(e.history) > historyLimit {
+		e.history = e.history[1:]
+	}
+	if e.historyPath == "" {
+		return
+	}
+	file, err := os.OpenFile(e.historyPath, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o600)
+	if err != nil {
+		return
+	}
+	defer file.Close()
+	io.WriteString(file, line+"\n")
+}
@@ -0,0 +1,172 @@
+package repl
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// editor cria um editor sem terminal nem arquivo de histórico, com text
+// digitado e o cursor no fim
+func editor(text string, complete completer) *lineEditor {
+	e := &lineEditor{out: io.Discard, complete: complete}
+	e.insert([]rune(text))
+	return e
+}
+
+// O cursor anda e apaga um emoji inteiro, com modificadores e ZWJ
+func TestEditorGraphemes(t *testing.T) {
+	tests := []struct {
+		text string
+		want string // Texto depois de apagar para trás uma vez
+	}{
+		{"ab", "a"},
+		{"a🖨️", "a"},
+		{"a👨🏿‍💻", "a"},
+		{"a🤦🏿‍♂️", "a"},
+		{"ação", "açã"},
+		{"aç", "a"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			e := editor(tt.text, nil)
+			e.deleteBackward()
+			if got := string(e.buf); got != tt.want {
+				t.Errorf("apagar em %q = %q, esperado %q", tt.text, got, tt.want)
+			}
+
+			e = editor(tt.text, nil)
+			e.pos = e.prevBoundary(e.pos)
+			e.pos = e.nextBoundary(e.pos)
+			if e.pos != len(e.buf) {
+				t.Errorf("voltar e avançar em %q parou em %d, esperado %d", tt.text, e.pos, len(e.buf))
+			}
+		})
+	}
+}
+
+func TestEditorDeleteWord(t *testing.T) {
+	e := editor("✍️ total = soma  ", nil)
+	e.deleteWord()
+	if got := string(e.buf); got != "✍️ total = " {
+		t.Errorf("Ctrl-W = %q", got)
+	}
+}
+
+func TestEditorTab(t *testing.T) {
+	complete := func(prefix string) (int, []string) {
+		start := strings.LastIndexByte(prefix, ' ') + 1
+		var candidates []string
+		for _, name := range []string{"contador", "conta", "🖨️"} {
+			if strings.HasPrefix(name, prefix[start:]) || prefix[start:] == ":print:" && name == "🖨️" {
+				candidates = append(candidates, name)
+			}
+		}
+		return start, candidates
+	}
+
+	tests := []struct {
+		text  string
+		after string // Texto depois do cursor
+		want  string
+	}{
+		{"x = contad", "", "x = contador"},
+		{"x = co", "", "x = conta"},    // Estende até o prefixo comum
+		{"x = conta", "", "x = conta"}, // Já é o prefixo comum: lista as opções
+		{"x = zz", "", "x = zz"},       // Nenhuma opção
+		{":print:", " 1", "🖨️ 1"},      // Substitui só o texto antes do cursor
+		{"ação = contad", " + 1", "ação = contador + 1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			e := editor(tt.text+tt.after, complete)
+			e.pos = len([]rune(tt.text))
+			e.tab()
+			if got := string(e.buf); got != tt.want {
+				t.Errorf("Tab em %q = %q, esperado %q", tt.text, got, tt.want)
+			}
+			if want := len([]rune(tt.want)) - len([]rune(tt.after)); e.pos != want {
+				t.Errorf("cursor em %d, esperado %d", e.pos, want)
+			}
+		})
+	}
+}
+
+func TestCommonPrefix(t *testing.T) {
+	tests := []struct {
+		words []string
+		want  string
+	}{
+		{[]string{"contador", "conta"}, "conta"},
+		{[]string{"abc"}, "abc"},
+		{[]string{"a", "b"}, ""},
+		{[]string{"🖨️", "🖨"}, "🖨"}, // Não corta um rune ao meio
+		{[]string{"é", "ê"}, ""},
+	}
+	for _, tt := range tests {
+		if got := commonPrefix(tt.words); got != tt.want {
+			t.Errorf("commonPrefix(%q) = %q, esperado %q", tt.words, got, tt.want)
+		}
+	}
+}
+
+func TestHistory(t *testing.T) {
+	path := filepath.Join(t.TempDir(), historyFile)
+	e := &lineEditor{out: io.Discard, historyPath: path}
+	for _, line := range []string{"✍️ x = 1", "", "  ", "🖨️ x", "🖨️ x", "x++"} {
+		e.addHistory(line)
+	}
+	want := []string{"✍️ x = 1", "🖨️ x", "x++"} // Sem linhas vazias nem repetidas
+	if !slices.Equal(e.history, want) {
+		t.Errorf("histórico = %q, esperado %q", e.history, want)
+	}
+
+	// Uma nova sessão carrega o histórico do arquivo
+	loaded := &lineEditor{out: io.Discard, historyPath: path}
+	loaded.loadHistory()
+	if !slices.Equal(loaded.history, want) {
+		t.Errorf("histórico carregado = %q, esperado %q", loaded.history, want)
+	}
+
+	// Navegar no histórico guarda a linha sendo digitada
+	loaded.insert([]rune("rascunho"))
+	index, draft := len(loaded.history), []rune(nil)
+	index, draft = loaded.historyMove(index, -1, draft)
+	index, draft = loaded.historyMove(index, -1, draft)
+	if got := string(loaded.buf); got != "🖨️ x" {
+		t.Errorf("duas entradas para trás = %q", got)
+	}
+	index, draft = loaded.historyMove(index, 1, draft)
+	index, _ = loaded.historyMove(index, 1, draft)
+	if got := string(loaded.buf); got != "rascunho" || index != len(loaded.history) {
+		t.Errorf("de volta ao fim = %q, esperado o rascunho", got)
+	}
+}
+
+// O arquivo de histórico é cortado nas últimas historyLimit entradas
+func TestHistoryLimit(t *testing.T) {
+	path := filepath.Join(t.TempDir(), historyFile)
+	var lines strings.Builder
+	for i := 0; i < historyLimit+10; i++ {
+		lines.WriteString("🖨️ " + strings.Repeat("x", i+1) + "\n")
+	}
+	if err := os.WriteFile(path, []byte(lines.String()), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	e := &lineEditor{out: io.Discard, historyPath: path}
+	e.loadHistory()
+	if len(e.history) != historyLimit || e.history[0] != "🖨️ "+strings.Repeat("x", 11) {
+		t.Fatalf("%d entradas carregadas, começando em %q", len(e.history), e.history[0])
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := strings.Count(string(data), "\n"); n != historyLimit {
+		t.Errorf("arquivo com %d linhas, esperado %d", n, historyLimit)
+	}
+}
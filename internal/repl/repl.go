@@ -0,0 +1,308 @@
+// Package repl implementa o modo interativo do emojilang: lê uma entrada por
+// vez, executa no mesmo interpretador e mostra o valor das expressões.
+package repl
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"melhorzin-lang/internal/ast"
+	"melhorzin-lang/internal/interpreter"
+	"melhorzin-lang/internal/lexer"
+	"melhorzin-lang/internal/parser"
+	"os"
+	"os/signal"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+const (
+	prompt             = "mlz> "
+	continuationPrompt = "...  "
+)
+
+const help = `Comandos:
+  :type nome    mostra o tipo de uma variável ou a assinatura de uma função
+  :vars         lista as variáveis e funções definidas
+  :load arquivo executa um arquivo nesta sessão
+  :reset        descarta todas as variáveis e funções
+  :help         mostra esta ajuda
+  :quit         sai (também Ctrl-D)
+
+Uma entrada com { ( ou string abertos continua na linha seguinte; duas
+linhas vazias seguidas descartam a entrada incompleta. Ctrl-C interrompe a
+execução em andamento.
+
+No terminal, as setas editam a linha e navegam no histórico. Tab completa
+nomes definidos e atalhos de palavras-chave: :print: vira 🖨️, :fn: vira ▶️.`
+
+// REPL é uma sessão interativa. As variáveis e funções definidas em uma
+// entrada continuam disponíveis nas seguintes.
+type REPL struct {
+	in     *bufio.Reader
+	out    io.Writer
+	interp *interpreter.Interpreter
+	editor *lineEditor // nil se a entrada não é um terminal
+}
+
+// New cria uma sessão que lê as entradas de in e escreve prompts, resultados
+// e erros em out. Os programas executados também leem (⌨️) de in. Se in e out
+// forem um terminal, as linhas podem ser editadas e ficam no histórico.
+func New(in io.Reader, out io.Writer) *REPL {
+	r := &REPL{in: bufio.NewReader(in), out: out}
+	r.reset()
+	if terminal(in) && terminal(out) {
+		r.editor = newLineEditor(int(in.(*os.File).Fd()), r.in, out, r.complete)
+	}
+	return r
+}
+
+// terminal indica se f é um arquivo ligado a um terminal
+func terminal(f interface{}) bool {
+	file, ok := f.(*os.File)
+	return ok && isTerminal(int(file.Fd()))
+}
+
+// reset cria um interpretador novo, sem variáveis definidas
+func (r *REPL) reset() {
+	r.interp = interpreter.NewInterpreter(interpreter.Trusted())
+	r.interp.SetIO(r.in, r.out, r.out)
+}
+
+// Run lê e executa entradas até o fim da entrada ou :quit.
+func (r *REPL) Run() error {
+	fmt.Fprintln(r.out, "Melhorzin — :help para ajuda, :quit para sair")
+
+	var pending []string // Linhas de uma entrada incompleta
+	for {
+		current := prompt
+		if len(pending) > 0 {
+			current = continuationPrompt
+		}
+		line, err := r.readLine(current)
+		if err == errInterrupted {
+			pending = nil
+			continue
+		}
+		if err == io.EOF {
+			if r.editor == nil {
+				fmt.Fprintln(r.out)
+			}
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if len(pending) == 0 {
+			trimmed := strings.TrimSpace(line)
+			if trimmed == "" {
+				continue
+			}
+			if strings.HasPrefix(trimmed, ":") {
+				if quit := r.command(trimmed); quit {
+					return nil
+				}
+				continue
+			}
+		} else if line == "" && pending[len(pending)-1] == "" {
+			fmt.Fprintln(r.out, "(entrada descartada)")
+			pending = nil
+			continue
+		}
+
+		pending = append(pending, line)
+		source := strings.Join(pending, "\n")
+		if incomplete(source) {
+			continue
+		}
+		pending = nil
+		if quit := r.eval(source); quit {
+			return nil
+		}
+	}
+}
+
+// readLine mostra o prompt e lê uma linha, sem a quebra de linha
+func (r *REPL) readLine(prompt string) (string, error) {
+	if r.editor != nil {
+		return r.editor.readLine(prompt)
+	}
+	fmt.Fprint(r.out, prompt)
+	line, err := r.in.ReadString('\n')
+	if err != nil && (err != io.EOF || line == "") {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// incomplete indica se source termina com chaves ou parênteses abertos, ou no
+// meio de uma string
+func incomplete(source string) bool {
+	lex := lexer.NewLexer(source)
+	lex.SetErrorOutput(io.Discard)
+	depth := 0
+	for token := range lex.Tokens() {
+		switch token.Type {
+		case lexer.TokenLBrace, lexer.TokenLParen:
+			depth++
+		case lexer.TokenRBrace, lexer.TokenRParen:
+			depth--
+		}
+	}
+	return depth > 0 || lex.Incomplete()
+}
+
+// eval analisa e executa uma entrada completa. O valor das expressões é
+// mostrado pelo próprio interpretador. Retorna true se o programa chamou
+// sair, o que encerra a sessão.
+func (r *REPL) eval(source string) (quit bool) {
+	nodes, err := r.parse(source)
+	if err != nil {
+		fmt.Fprintln(r.out, err)
+		return false
+	}
+
+	// Ctrl-C interrompe só a execução atual, não a sessão
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	_, err = r.interp.Interpret(ctx, nodes)
+	if _, exited := err.(*interpreter.Exit); exited {
+		return true
+	}
+	if err != nil {
+		fmt.Fprintln(r.out, err)
+	}
+	return false
+}
+
+// parse analisa source com os tipos das variáveis já definidas na sessão
+func (r *REPL) parse(source string) (nodes []ast.Node, err error) {
+	lex := lexer.NewLexer(source)
+	lex.SetErrorOutput(io.Discard)
+	defer func() {
+		var messages []string
+		for _, lexErr := range lex.Errors() {
+			messages = append(messages, lexErr.Error())
+		}
+		if e := recover(); e != nil {
+			message, ok := e.(string)
+			if !ok {
+				panic(e)
+			}
+			messages = append(messages, message)
+		}
+		// Um erro léxico também impede a execução da entrada
+		if len(messages) > 0 {
+			nodes, err = nil, errors.New("Erro de sintaxe: "+strings.Join(messages, "\nErro de sintaxe: "))
+		}
+	}()
+	return parser.NewParserWithTypes(lex, r.interp.Types()).Parse(), nil
+}
+
+// command executa um comando iniciado por :. Retorna true para sair.
+func (r *REPL) command(line string) bool {
+	name, arg, _ := strings.Cut(line, " ")
+	arg = strings.TrimSpace(arg)
+
+	switch name {
+	case ":quit", ":q":
+		return true
+	case ":help", ":h":
+		fmt.Fprintln(r.out, help)
+	case ":type", ":t":
+		if arg == "" {
+			fmt.Fprintln(r.out, "Uso: :type nome")
+			break
+		}
+		fmt.Fprintln(r.out, r.describe(arg))
+	case ":vars":
+		r.listVars()
+	case ":load", ":l":
+		if arg == "" {
+			fmt.Fprintln(r.out, "Uso: :load arquivo")
+			break
+		}
+		source, err := os.ReadFile(arg)
+		if err != nil {
+			fmt.Fprintf(r.out, "Erro ao ler arquivo: %v\n", err)
+			break
+		}
+		return r.eval(string(source))
+	case ":reset":
+		r.reset()
+		fmt.Fprintln(r.out, "Sessão reiniciada")
+	default:
+		fmt.Fprintf(r.out, "Comando desconhecido: %s (use :help)\n", name)
+	}
+	return false
+}
+
+// describe descreve o tipo de uma variável, ou a assinatura de uma função
+func (r *REPL) describe(name string) string {
+	value, exists := r.interp.GetVariable(name)
+	if !exists {
+		return fmt.Sprintf("%s não está definida", name)
+	}
+	switch fn := value.(type) {
+	case *ast.FunctionNode:
+		return signature(fn)
+	case *interpreter.NativeFunction:
+		return fmt.Sprintf("%s: função nativa", name)
+	}
+
+	typ := r.interp.GetVariableType(name)
+	if typ == ast.TypeAny {
+		typ = interpreter.TypeOfValue(value)
+	}
+	return fmt.Sprintf("%s: %s", name, typ)
+}
+
+// signature formata a assinatura de uma função: soma(a: NUMBER, b): NUMBER
+func signature(fn *ast.FunctionNode) string {
+	params := make([]string, len(fn.Parameters))
+	for i, param := range fn.Parameters {
+		params[i] = param
+		if fn.ParamTypes[i] != ast.TypeAny {
+			params[i] += ": " + string(fn.ParamTypes[i])
+		}
+	}
+	text := fmt.Sprintf("▶️ %s(%s)", fn.Name, strings.Join(params, ", "))
+	if fn.ReturnType != ast.TypeAny {
+		text += ": " + string(fn.ReturnType)
+	}
+	return text
+}
+
+// listVars lista as variáveis e funções definidas pelo usuário, em ordem
+// alfabética. Funções nativas ficam de fora.
+func (r *REPL) listVars() {
+	vars := r.interp.Variables()
+	var names []string
+	for name, value := range vars {
+		if _, native := value.(*interpreter.NativeFunction); !native {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		fmt.Fprintln(r.out, "Nenhuma variável definida")
+		return
+	}
+	slices.Sort(names)
+
+	for _, name := range names {
+		value := vars[name]
+		if text, ok := value.(string); ok {
+			fmt.Fprintf(r.out, "%s = %s\n", r.describe(name), strconv.Quote(text))
+			continue
+		}
+		if fn, ok := value.(*ast.FunctionNode); ok {
+			fmt.Fprintln(r.out, signature(fn))
+			continue
+		}
+		fmt.Fprintf(r.out, "%s = %s\n", r.describe(name), interpreter.Stringify(value))
+	}
+}
@@ -0,0 +1,28 @@
+package repl
+
+import (
+	"strings"
+	"testing"
+)
+
+// Uma entrada com tokens soltos é um erro de sintaxe e não é executada; a
+// sessão continua
+func TestRunRejectsStrayTokens(t *testing.T) {
+	var out strings.Builder
+	input := "✍️ z = 1 -- 2\n🖨️ z\n✍️ y = 3 }\n🖨️ y\n"
+	if err := New(strings.NewReader(input), &out).Run(); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"Erro de sintaxe: Token inesperado DECREMENT (valor: --)",
+		"Erro de sintaxe: Token inesperado RBRACE (valor: })",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("saída sem %q:\n%s", want, out.String())
+		}
+	}
+	// Nenhuma das atribuições foi executada
+	if strings.Contains(out.String(), "1\n") || strings.Contains(out.String(), "3\n") {
+		t.Errorf("entrada com token solto foi executada:\n%s", out.String())
+	}
+}
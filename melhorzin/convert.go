@@ -0,0 +1,227 @@
+package melhorzin
+
+import (
+	"fmt"
+	"math"
+	"melhorzin-lang/internal/ast"
+	"melhorzin-lang/internal/interpreter"
+	"reflect"
+)
+
+// fromGo converte um valor Go para um valor da linguagem. Inteiros de
+// qualquer tamanho viram int, floats só são aceitos se forem inteiros,
+// slices viram listas e funções Go viram funções nativas com o nome
+// informado.
+func fromGo(name string, value interface{}) (interface{}, error) {
+	switch value.(type) {
+	case nil:
+		return nil, nil
+	case *ast.FunctionNode, *interpreter.NativeFunction:
+		return value, nil
+	}
+
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Bool:
+		return v.Bool(), nil
+	case reflect.String:
+		return v.String(), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return int(v.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if v.Uint() > math.MaxInt {
+			return nil, fmt.Errorf("número %d fora do intervalo de inteiros", v.Uint())
+		}
+		return int(v.Uint()), nil
+	case reflect.Float32, reflect.Float64:
+		f := v.Float()
+		if f != math.Trunc(f) || f < math.MinInt64 || f >= math.MaxInt64 {
+			return nil, fmt.Errorf("número %v não é um inteiro", f)
+		}
+		return int(f), nil
+	case reflect.Slice, reflect.Array:
+		list := make([]interface{}, v.Len())
+		for i := range list {
+			item, err := fromGo(name, v.Index(i).Interface())
+			if err != nil {
+				return nil, err
+			}
+			list[i] = item
+		}
+		return list, nil
+	case reflect.Func:
+		return wrapFunc(name, value)
+	}
+	return nil, fmt.Errorf("tipo Go não suportado: %T", value)
+}
+
+// toGo converte um valor da linguagem para Go. Listas viram []interface{}
+// novas, para que o host não altere as do programa, e funções viram Function.
+func toGo(value interface{}) interface{} {
+	switch v := value.(type) {
+	case []interface{}:
+		list := make([]interface{}, len(v))
+		for i, item := range v {
+			list[i] = toGo(item)
+		}
+		return list
+	case *ast.FunctionNode:
+		return Function{Name: v.Name}
+	case *interpreter.NativeFunction:
+		return Function{Name: v.Name}
+	}
+	return value
+}
+
+// toGoType converte um valor da linguagem para o tipo Go t de um parâmetro
+func toGoType(value interface{}, t reflect.Type) (reflect.Value, error) {
+	result := reflect.New(t).Elem()
+	mismatch := func(expected ast.Type) (reflect.Value, error) {
+		return result, fmt.Errorf("esperado %s, recebido %s", expected, interpreter.TypeOfValue(value))
+	}
+
+	switch t.Kind() {
+	case reflect.Interface:
+		if value != nil {
+			result.Set(reflect.ValueOf(value))
+		}
+	case reflect.Bool:
+		b, ok := value.(bool)
+		if !ok {
+			return mismatch(ast.TypeBool)
+		}
+		result.SetBool(b)
+	case reflect.String:
+		s, ok := value.(string)
+		if !ok {
+			return mismatch(ast.TypeString)
+		}
+		result.SetString(s)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, ok := value.(int)
+		if !ok {
+			return mismatch(ast.TypeNumber)
+		}
+		if result.OverflowInt(int64(n)) {
+			return result, fmt.Errorf("número %d não cabe em %s", n, t)
+		}
+		result.SetInt(int64(n))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, ok := value.(int)
+		if !ok {
+			return mismatch(ast.TypeNumber)
+		}
+		if n < 0 || result.OverflowUint(uint64(n)) {
+			return result, fmt.Errorf("número %d não cabe em %s", n, t)
+		}
+		result.SetUint(uint64(n))
+	case reflect.Float32, reflect.Float64:
+		n, ok := value.(int)
+		if !ok {
+			return mismatch(ast.TypeNumber)
+		}
+		result.SetFloat(float64(n))
+	}
+	return result, nil
+}
+
+// supportedType indica se valores do tipo Go t podem ser trocados com a
+// linguagem
+func supportedType(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Bool, reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	case reflect.Interface:
+		return t.NumMethod() == 0
+	}
+	return false
+}
+
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+// wrapFunc transforma uma função Go em uma função nativa da linguagem,
+// validando a assinatura uma única vez
+func wrapFunc(name string, fn interface{}) (*interpreter.NativeFunction, error) {
+	v := reflect.ValueOf(fn)
+	if v.Kind() != reflect.Func {
+		return nil, fmt.Errorf("%s não é uma função: %T", name, fn)
+	}
+	t := v.Type()
+
+	for i := 0; i < t.NumIn(); i++ {
+		in := t.In(i)
+		if t.IsVariadic() && i == t.NumIn()-1 {
+			in = in.Elem()
+		}
+		if !supportedType(in) {
+			return nil, fmt.Errorf("função %s: tipo de parâmetro não suportado: %s", name, in)
+		}
+	}
+
+	returnsError := t.NumOut() > 0 && t.Out(t.NumOut()-1) == errorType
+	numValues := t.NumOut()
+	if returnsError {
+		numValues--
+	}
+	if numValues > 1 || (numValues == 1 && !supportedType(t.Out(0))) {
+		return nil, fmt.Errorf("função %s: deve retornar no máximo um valor de tipo suportado e um error", name)
+	}
+
+	call := func(_ *interpreter.Env, args []interface{}) interface{} {
+		fixed := t.NumIn()
+		if t.IsVariadic() {
+			fixed--
+		}
+		if len(args) < fixed || (!t.IsVariadic() && len(args) > fixed) {
+			raise(ErrArgument, "Número incorreto de argumentos para função %s", name)
+		}
+
+		in := make([]reflect.Value, len(args))
+		for i, arg := range args {
+			paramType := t.In(min(i, t.NumIn()-1))
+			if t.IsVariadic() && i >= fixed {
+				paramType = paramType.Elem()
+			}
+			converted, err := toGoType(arg, paramType)
+			if err != nil {
+				raise(ErrType, "Tipo incorreto para argumento %d da função %s: %v", i+1, name, err)
+			}
+			in[i] = converted
+		}
+
+		out := callHost(name, v, in)
+		if returnsError {
+			if err, _ := out[len(out)-1].Interface().(error); err != nil {
+				raise(ErrHost, "%s: %v", name, err)
+			}
+		}
+		if numValues == 0 {
+			return nil
+		}
+		result, err := fromGo(name, out[0].Interface())
+		if err != nil {
+			raise(ErrType, "Tipo de retorno incorreto para função %s: %v", name, err)
+		}
+		return result
+	}
+	return &interpreter.NativeFunction{Name: name, Fn: call}, nil
+}
+
+// callHost chama a função Go fn. Um panic dentro dela vira um erro de
+// execução ErrHost, capturável pelo programa, em vez de derrubar o host.
+func callHost(name string, fn reflect.Value, in []reflect.Value) []reflect.Value {
+	defer func() {
+		if r := recover(); r != nil {
+			raise(ErrHost, "%s: panic: %v", name, r)
+		}
+	}()
+	return fn.Call(in)
+}
+
+// raise lança um erro de execução dentro de uma função nativa
+func raise(kind ErrorKind, format string, args ...interface{}) {
+	panic(&RuntimeError{Kind: kind, Message: fmt.Sprintf(format, args...)})
+}
@@ -0,0 +1,270 @@
+// Package melhorzin permite executar programas Melhorzin a partir de Go,
+// trocando valores com o programa e expondo funções do host.
+//
+//	m := melhorzin.New(melhorzin.Options{})
+//	m.RegisterFunc("dobro", func(n int) int { return n * 2 })
+//	m.SetGlobal("nome", "Melhorzin")
+//	result, err := m.Eval(ctx, `dobro(21)`)
+//
+// Os valores da linguagem são convertidos de e para Go automaticamente:
+// números viram int, textos string e booleanos bool.
+package melhorzin
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"melhorzin-lang/internal/ast"
+	"melhorzin-lang/internal/interpreter"
+	"melhorzin-lang/internal/lexer"
+	"melhorzin-lang/internal/parser"
+	"strings"
+)
+
+// RuntimeError é um erro de execução do programa (tipo incompatível, estouro
+// de inteiro, função não definida, ...).
+type RuntimeError = interpreter.RuntimeError
+
+// ErrorKind classifica um RuntimeError.
+type ErrorKind = interpreter.ErrorKind
+
+const (
+	ErrType       = interpreter.ErrType
+	ErrName       = interpreter.ErrName
+	ErrArgument   = interpreter.ErrArgument
+	ErrFormat     = interpreter.ErrFormat
+	ErrOverflow   = interpreter.ErrOverflow
+	ErrValue      = interpreter.ErrValue
+	ErrInput      = interpreter.ErrInput
+	ErrResource   = interpreter.ErrResource
+	ErrPermission = interpreter.ErrPermission
+	ErrIO         = interpreter.ErrIO
+	ErrHost       = interpreter.ErrHost
+)
+
+// Interrupt é o erro retornado quando a execução é interrompida de fora do
+// programa: pelo cancelamento ou prazo do contexto, ou pelo limite de passos.
+// Use errors.Is com context.Canceled, context.DeadlineExceeded ou
+// ErrStepLimit para saber o motivo.
+type Interrupt = interpreter.Interrupt
+
+// Exit é o erro retornado quando o programa chama sair(código), o que exige
+// a capacidade Exit. Não é uma falha: o host decide o que fazer com Code.
+type Exit = interpreter.Exit
+
+// ErrStepLimit indica que a execução excedeu Options.MaxSteps.
+var ErrStepLimit = interpreter.ErrStepLimit
+
+// Function é o valor Go de uma função da linguagem, retornado por Global,
+// Eval e Call. Ela não pode ser chamada diretamente: use Call com Name.
+type Function struct {
+	Name string
+}
+
+// Capabilities define o que um programa pode fazer fora do interpretador:
+// ler e escrever arquivos dentro de diretórios raiz (lerArquivo,
+// escreverArquivo), ler variáveis de ambiente (ambiente), consultar o relógio
+// (agora), gerar números aleatórios (aleatorio) e imprimir (🖨️). Uma
+// operação negada lança um erro de execução ErrPermission.
+type Capabilities = interpreter.Capabilities
+
+// Sandbox retorna as capacidades de um sandbox puro, em que o programa só
+// calcula: nada fora do interpretador é permitido, nem imprimir.
+func Sandbox() Capabilities {
+	return interpreter.Sandbox()
+}
+
+// Trusted retorna as capacidades de um programa confiável, em que tudo é
+// permitido.
+func Trusted() Capabilities {
+	return interpreter.Trusted()
+}
+
+// SyntaxError é um erro de análise do código fonte, léxico ou do parser.
+// Nenhuma instrução do trecho é executada quando ele ocorre.
+type SyntaxError struct {
+	Message string
+}
+
+func (e *SyntaxError) Error() string {
+	return "Erro de sintaxe: " + e.Message
+}
+
+// Options configura um Interpreter.
+type Options struct {
+	// Keywords substitui o dicionário de palavras-chave padrão, por exemplo
+	// para aceitar aliases localizados
+	Keywords *lexer.Keywords
+
+	// TextSyntax aceita também a sintaxe textual (print, let, fn, ...), que
+	// deixam de poder ser usados como identificadores. Ignorado se Keywords
+	// for informado.
+	TextSyntax bool
+
+	// Stdout recebe o que o programa imprime e Stderr os diagnósticos (erros
+	// léxicos). Se nil, a saída é descartada.
+	Stdout io.Writer
+	Stderr io.Writer
+
+	// Stdin é a entrada lida pelo programa. Se nil, o programa lê uma
+	// entrada vazia.
+	Stdin io.Reader
+
+	// Capabilities define o que o programa pode fazer fora do
+	// interpretador. Se nil, o programa só pode imprimir em Stdout.
+	Capabilities *Capabilities
+
+	// MaxSteps limita quantos nós da AST cada Eval ou Call pode avaliar,
+	// protegendo o host de programas que não terminam. 0 significa sem
+	// limite.
+	MaxSteps int
+
+	// MaxAlloc limita quantos bytes cada Eval ou Call pode alocar em
+	// strings, somando todas as alocações. Ao exceder o limite o programa
+	// recebe um erro ErrResource, que pode capturar; se não capturar, o Eval
+	// retorna esse erro. 0 significa sem limite.
+	MaxAlloc int
+
+	// MaxDepth limita quantas chamadas de função podem estar aninhadas. Uma
+	// recursão mais funda recebe um erro ErrResource, que pode capturar. 0
+	// significa o limite padrão, de 10000 chamadas.
+	MaxDepth int
+}
+
+// Interpreter é uma instância da linguagem com suas variáveis globais. As
+// variáveis e funções definidas por um Eval continuam visíveis nos seguintes.
+// Um Interpreter não deve ser usado por várias goroutines ao mesmo tempo.
+type Interpreter struct {
+	interp   *interpreter.Interpreter
+	keywords *lexer.Keywords
+	stderr   io.Writer
+}
+
+// New cria um interpretador.
+func New(opts Options) *Interpreter {
+	keywords := opts.Keywords
+	switch {
+	case keywords != nil:
+	case opts.TextSyntax:
+		keywords = lexer.TextKeywords()
+	default:
+		keywords = lexer.DefaultKeywords()
+	}
+	stderr := opts.Stderr
+	if stderr == nil {
+		stderr = io.Discard
+	}
+
+	caps := Capabilities{Stdout: true}
+	if opts.Capabilities != nil {
+		caps = *opts.Capabilities
+	}
+
+	interp := interpreter.NewInterpreter(caps)
+	interp.SetEcho(false)
+	interp.SetIO(opts.Stdin, opts.Stdout, stderr)
+	interp.SetMaxSteps(opts.MaxSteps)
+	interp.SetMaxAlloc(opts.MaxAlloc)
+	interp.SetMaxDepth(opts.MaxDepth)
+	return &Interpreter{interp: interp, keywords: keywords, stderr: stderr}
+}
+
+// Eval analisa e executa source, retornando o valor da última instrução
+// convertido para Go. Erros de sintaxe retornam *SyntaxError, erros de
+// execução *RuntimeError e o cancelamento de ctx ou o limite de passos
+// *Interrupt, e um programa que chamou sair retorna *Exit.
+func (m *Interpreter) Eval(ctx context.Context, source string) (interface{}, error) {
+	nodes, err := m.parse(source)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := m.interp.Interpret(ctx, nodes)
+	if err != nil {
+		return nil, err
+	}
+	return toGo(result), nil
+}
+
+// parse analisa o código inteiro antes da execução, usando os tipos das
+// variáveis já definidas
+func (m *Interpreter) parse(source string) (nodes []ast.Node, err error) {
+	lex := lexer.NewLexerWithKeywords(source, m.keywords)
+	lex.SetErrorOutput(m.stderr)
+	defer func() {
+		var messages []string
+		for _, lexErr := range lex.Errors() {
+			messages = append(messages, lexErr.Error())
+		}
+		if r := recover(); r != nil {
+			message, ok := r.(string)
+			if !ok {
+				panic(r)
+			}
+			messages = append(messages, message)
+		}
+		// Um erro léxico também torna o código inválido, mesmo que o parser
+		// consiga continuar
+		if len(messages) > 0 {
+			nodes, err = nil, &SyntaxError{Message: strings.Join(messages, "; ")}
+		}
+	}()
+	return parser.NewParserWithTypes(lex, m.interp.Types()).Parse(), nil
+}
+
+// SetGlobal define uma variável global. value pode ser um número inteiro,
+// string, bool, nil, um slice desses valores (uma lista) ou uma função Go
+// (veja RegisterFunc).
+func (m *Interpreter) SetGlobal(name string, value interface{}) error {
+	converted, err := fromGo(name, value)
+	if err != nil {
+		return err
+	}
+	m.interp.SetVariable(name, converted)
+	return nil
+}
+
+// Global retorna o valor de uma variável global convertido para Go, como em
+// Eval; uma função retorna Function. ok é false se a variável não existe.
+func (m *Interpreter) Global(name string) (value interface{}, ok bool) {
+	value, ok = m.interp.GetVariable(name)
+	return toGo(value), ok
+}
+
+// Call chama uma função global, definida no programa ou registrada pelo host,
+// e retorna seu resultado convertido para Go.
+func (m *Interpreter) Call(name string, args ...interface{}) (interface{}, error) {
+	return m.CallContext(context.Background(), name, args...)
+}
+
+// CallContext é como Call, mas interrompe a função se ctx for cancelado.
+func (m *Interpreter) CallContext(ctx context.Context, name string, args ...interface{}) (interface{}, error) {
+	values := make([]interface{}, len(args))
+	for i, arg := range args {
+		value, err := fromGo(name, arg)
+		if err != nil {
+			return nil, fmt.Errorf("argumento %d de %s: %w", i+1, name, err)
+		}
+		values[i] = value
+	}
+
+	result, err := m.interp.Call(ctx, name, values)
+	if err != nil {
+		return nil, err
+	}
+	return toGo(result), nil
+}
+
+// RegisterFunc expõe uma função Go ao programa com o nome name. Os parâmetros
+// podem ser de tipos inteiros, string, bool ou interface{}, e a função pode
+// ser variádica. Ela pode retornar nada, um valor, um error, ou um valor e um
+// error; um error não nulo, ou um panic dentro da função, vira um erro de
+// execução ErrHost, capturável com 👨🏿‍💻/🤦🏿‍♂️.
+func (m *Interpreter) RegisterFunc(name string, fn interface{}) error {
+	native, err := wrapFunc(name, fn)
+	if err != nil {
+		return err
+	}
+	m.interp.SetVariable(name, native)
+	return nil
+}
@@ -0,0 +1,307 @@
+package melhorzin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestFromGo(t *testing.T) {
+	tests := []struct {
+		name  string
+		value interface{}
+		want  interface{}
+		err   string // Trecho da mensagem de erro esperada; vazio se não há erro
+	}{
+		{"nil", nil, nil, ""},
+		{"bool", true, true, ""},
+		{"string", "olá", "olá", ""},
+		{"int8", int8(-5), -5, ""},
+		{"uint16", uint16(7), 7, ""},
+		{"uint64 grande", uint64(math.MaxUint64), nil, "fora do intervalo"},
+		{"float inteiro", 3.0, 3, ""},
+		{"float fracionário", 2.5, nil, "não é um inteiro"},
+		{"slice", []int{1, 2}, []interface{}{1, 2}, ""},
+		{"slice aninhado", [][]string{{"a"}, {}}, []interface{}{[]interface{}{"a"}, []interface{}{}}, ""},
+		{"array", [2]bool{true, false}, []interface{}{true, false}, ""},
+		{"map", map[string]int{}, nil, "tipo Go não suportado"},
+		{"struct", struct{}{}, nil, "tipo Go não suportado"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := fromGo("x", tt.value)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("fromGo(%#v) = erro %v, esperado erro com %q", tt.value, err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("fromGo(%#v): erro inesperado %v", tt.value, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("fromGo(%#v) = %#v, esperado %#v", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEvalResult(t *testing.T) {
+	tests := []struct {
+		source string
+		want   interface{}
+	}{
+		{`1 + 2`, 3},
+		{`"a" . "b"`, "ab"},
+		{`1 🟰 1`, true},
+		{`✍️ x = 4
+x ✖️ x`, 16},
+		{`▶️ dobro(n: 🔢) { ↩️ n * 2 }
+dobro(21)`, 42},
+		{`✍️ x = 1`, nil},
+		{`▶️ f() {}
+f`, Function{Name: "f"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.source, func(t *testing.T) {
+			got, err := New(Options{}).Eval(context.Background(), tt.source)
+			if err != nil {
+				t.Fatalf("Eval: erro inesperado %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Eval = %#v, esperado %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTextSyntax(t *testing.T) {
+	tests := []struct {
+		name   string
+		text   bool
+		source string
+		want   interface{}
+	}{
+		{"palavras textuais", true, "let x = 2\nx * 3", 6},
+		{"misturada com emoji", true, "fn dobro(n: num) { return n ✖️ 2 }\ndobro(4)", 8},
+		{"identificador sem TextSyntax", false, "✍️ let = 5\nlet", 5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := New(Options{TextSyntax: tt.text}).Eval(context.Background(), tt.source)
+			if err != nil {
+				t.Fatalf("Eval: erro inesperado %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Eval = %#v, esperado %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSetGlobalAndCall(t *testing.T) {
+	m := New(Options{})
+	if err := m.SetGlobal("nomes", []string{"ana", "bia"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Eval(context.Background(), `▶️ primeiro(lista) { ↩️ item(lista, 0) }`); err != nil {
+		t.Fatal(err)
+	}
+	got, err := m.Call("primeiro", []string{"x", "y"})
+	if err != nil || got != "x" {
+		t.Errorf("Call(primeiro) = %#v, %v; esperado \"x\"", got, err)
+	}
+	if value, ok := m.Global("nomes"); !ok || !reflect.DeepEqual(value, []interface{}{"ana", "bia"}) {
+		t.Errorf("Global(nomes) = %#v, %v", value, ok)
+	}
+	// Alterar a lista retornada não altera a do programa
+	value, _ := m.Global("nomes")
+	value.([]interface{})[0] = "eva"
+	if got, err := m.Eval(context.Background(), `item(nomes, 0)`); err != nil || got != "ana" {
+		t.Errorf("item(nomes, 0) = %#v, %v; Global deveria retornar uma cópia da lista", got, err)
+	}
+	if value, ok := m.Global("primeiro"); !ok || value != (Function{Name: "primeiro"}) {
+		t.Errorf("Global(primeiro) = %#v, %v; esperado Function", value, ok)
+	}
+	if _, ok := m.Global("naoExiste"); ok {
+		t.Error("Global(naoExiste): esperado ok = false")
+	}
+	if err := m.SetGlobal("x", map[int]int{}); err == nil {
+		t.Error("SetGlobal com um map: esperado erro")
+	}
+}
+
+func TestRegisterFuncSignatures(t *testing.T) {
+	tests := []struct {
+		name string
+		fn   interface{}
+		ok   bool
+	}{
+		{"sem parâmetros", func() {}, true},
+		{"inteiros", func(a int, b int64, c uint8) int { return 0 }, true},
+		{"string e bool", func(s string, b bool) string { return s }, true},
+		{"interface vazia", func(v interface{}) interface{} { return v }, true},
+		{"variádica", func(prefix string, rest ...int) int { return 0 }, true},
+		{"valor e error", func() (int, error) { return 0, nil }, true},
+		{"só error", func() error { return nil }, true},
+		{"não é função", 42, false},
+		{"parâmetro map", func(map[string]int) {}, false},
+		{"parâmetro struct", func(struct{}) {}, false},
+		{"interface com métodos", func(fmt.Stringer) {}, false},
+		{"dois valores", func() (int, int) { return 0, 0 }, false},
+		{"retorno não suportado", func() []int { return nil }, false},
+		{"três resultados", func() (int, string, error) { return 0, "", nil }, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := New(Options{}).RegisterFunc("f", tt.fn)
+			if tt.ok && err != nil {
+				t.Errorf("RegisterFunc: erro inesperado %v", err)
+			}
+			if !tt.ok && err == nil {
+				t.Error("RegisterFunc: esperado erro")
+			}
+		})
+	}
+}
+
+func TestRegisterFuncCalls(t *testing.T) {
+	m := New(Options{})
+	funcs := map[string]interface{}{
+		"soma": func(nums ...int) int {
+			total := 0
+			for _, n := range nums {
+				total += n
+			}
+			return total
+		},
+		"repetir": func(s string, n uint8) string { return strings.Repeat(s, int(n)) },
+		"dividir": func(a, b int) (int, error) {
+			if b == 0 {
+				return 0, errors.New("divisão por zero")
+			}
+			return a / b, nil
+		},
+		"fracao": func() float64 { return 0.5 },
+		"falhar": func(n int) int { return []int{}[n] },
+	}
+	for name, fn := range funcs {
+		if err := m.RegisterFunc(name, fn); err != nil {
+			t.Fatalf("RegisterFunc(%s): %v", name, err)
+		}
+	}
+
+	tests := []struct {
+		source string
+		want   interface{}
+		kind   ErrorKind // Tipo do erro esperado; vazio se não há erro
+	}{
+		{`soma()`, 0, ""},
+		{`soma(1, 2, 3)`, 6, ""},
+		{`repetir("ab", 3)`, "ababab", ""},
+		{`dividir(7, 2)`, 3, ""},
+		{`soma("1")`, nil, ErrType},
+		{`repetir("a", -1)`, nil, ErrType},
+		{`repetir("a")`, nil, ErrArgument},
+		{`dividir(1, 2, 3)`, nil, ErrArgument},
+		{`dividir(1, 0)`, nil, ErrHost},
+		{`fracao()`, nil, ErrType},
+		{`falhar(1)`, nil, ErrHost},
+		{`✍️ r = ""
+👨🏿‍💻 { falhar(1) } 🤦🏿‍♂️ { ✍️ r = "capturado" }
+r`, "capturado", ""},
+		{`✍️ r = ""
+👨🏿‍💻 { dividir(1, 0) } 🤦🏿‍♂️ { ✍️ r = erro }
+r`, "dividir: divisão por zero", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.source, func(t *testing.T) {
+			got, err := m.Eval(context.Background(), tt.source)
+			if tt.kind != "" {
+				var runtimeErr *RuntimeError
+				if !errors.As(err, &runtimeErr) || runtimeErr.Kind != tt.kind {
+					t.Fatalf("Eval = erro %v, esperado erro do tipo %s", err, tt.kind)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Eval: erro inesperado %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Eval = %#v, esperado %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEvalErrors(t *testing.T) {
+	trusted := Trusted()
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tests := []struct {
+		name   string
+		opts   Options
+		ctx    context.Context
+		source string
+		check  func(t *testing.T, err error)
+	}{
+		{"sintaxe", Options{}, context.Background(), `🖨️ (1`, isSyntaxError},
+		{"erro léxico", Options{}, context.Background(), `🖨️ 1 $ 2`, isSyntaxError},
+		{"token solto", Options{}, context.Background(), `1 = 2 }`, isSyntaxError},
+		{"token solto em bloco", Options{}, context.Background(), `▶️ f() { ✍️ z = 1 -- 2 }`, isSyntaxError},
+		{"função indefinida", Options{}, context.Background(), `naoExiste()`, isRuntimeError(ErrName)},
+		{"tipo", Options{}, context.Background(), `1 + "a"`, isRuntimeError(ErrType)},
+		{"sem permissão", Options{}, context.Background(), `agora()`, isRuntimeError(ErrPermission)},
+		{"recursão", Options{MaxDepth: 50}, context.Background(), `▶️ f() { ↩️ f() }
+f()`, isRuntimeError(ErrResource)},
+		{"limite de passos", Options{MaxSteps: 100}, context.Background(), `▶️ f() { ↩️ f() }
+f()`, isInterrupt(ErrStepLimit)},
+		{"contexto cancelado", Options{}, cancelled, `1 + 1`, isInterrupt(context.Canceled)},
+		{"sair sem permissão", Options{}, context.Background(), `sair(3)`, isRuntimeError(ErrPermission)},
+		{"sair", Options{Capabilities: &trusted}, context.Background(), `sair(3)`, func(t *testing.T, err error) {
+			var exit *Exit
+			if !errors.As(err, &exit) || exit.Code != 3 {
+				t.Errorf("erro %v, esperado *Exit com código 3", err)
+			}
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := New(tt.opts).Eval(tt.ctx, tt.source)
+			tt.check(t, err)
+		})
+	}
+}
+
+func isSyntaxError(t *testing.T, err error) {
+	t.Helper()
+	var syntax *SyntaxError
+	if !errors.As(err, &syntax) {
+		t.Errorf("erro %v, esperado *SyntaxError", err)
+	}
+}
+
+func isRuntimeError(kind ErrorKind) func(t *testing.T, err error) {
+	return func(t *testing.T, err error) {
+		t.Helper()
+		var runtimeErr *RuntimeError
+		if !errors.As(err, &runtimeErr) || runtimeErr.Kind != kind {
+			t.Errorf("erro %v, esperado erro de execução do tipo %s", err, kind)
+		}
+	}
+}
+
+func isInterrupt(cause error) func(t *testing.T, err error) {
+	return func(t *testing.T, err error) {
+		t.Helper()
+		var interrupt *Interrupt
+		if !errors.As(err, &interrupt) || !errors.Is(err, cause) {
+			t.Errorf("erro %v, esperado *Interrupt por %v", err, cause)
+		}
+	}
+}